@@ -2,26 +2,43 @@ package app
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
+	"github.com/paulofeitor/kilabs-api/app/migrations"
 	"github.com/paulofeitor/kilabs-api/app/routes"
+	"github.com/paulofeitor/kilabs-api/app/store"
 	"github.com/paulofeitor/kilabs-api/config"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/julienschmidt/httprouter"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 type App struct {
+	Config *config.Config
 	Router *httprouter.Router
 	DB     *sql.DB
+	Store  store.Store
 }
 
-func (a *App) Initialize(config *config.Config) {
-	a.setDatabase(config)
+func (a *App) Initialize(config *config.Config) error {
+	a.Config = config
+	a.setLogging()
+	if err := a.setDatabase(); err != nil {
+		return err
+	}
+	if err := a.setStores(); err != nil {
+		return err
+	}
 	a.Router = httprouter.New()
 	a.setRoutes()
+	return nil
 }
 
 func (a *App) setRoutes() {
@@ -36,6 +53,15 @@ func (a *App) setRoutes() {
 	a.Router.PUT("/candidate/:candidate_id/slot/:slot_id", a.UpdateCandidateSlot)
 	a.Router.DELETE("/candidate/:candidate_id/slot/:slot_id", a.DeleteCandidateSlot)
 
+	a.Router.GET("/candidate/:candidate_id/exception", a.GetCandidateExceptions)
+	a.Router.POST("/candidate/:candidate_id/exception", a.AddCandidateException)
+	a.Router.PUT("/candidate/:candidate_id/exception/:exception_id", a.UpdateCandidateException)
+	a.Router.DELETE("/candidate/:candidate_id/exception/:exception_id", a.DeleteCandidateException)
+
+	a.Router.GET("/candidate/:candidate_id/calendar.ics", a.GetCandidateCalendar)
+	a.Router.POST("/candidate/:candidate_id/calendar_token", a.ResetCandidateCalendarToken)
+	a.Router.POST("/candidate/:candidate_id/import", a.ImportCandidateCalendar)
+
 	a.Router.GET("/interviewer", a.GetAllInterviewers)
 	a.Router.POST("/interviewer", a.AddInterviewer)
 	a.Router.GET("/interviewer/:interviewer_id", a.GetInterviewer)
@@ -47,103 +73,415 @@ func (a *App) setRoutes() {
 	a.Router.PUT("/interviewer/:interviewer_id/slot/:slot_id", a.UpdateInterviewerSlot)
 	a.Router.DELETE("/interviewer/:interviewer_id/slot/:slot_id", a.DeleteInterviewerSlot)
 
+	a.Router.GET("/interviewer/:interviewer_id/exception", a.GetInterviewerExceptions)
+	a.Router.POST("/interviewer/:interviewer_id/exception", a.AddInterviewerException)
+	a.Router.PUT("/interviewer/:interviewer_id/exception/:exception_id", a.UpdateInterviewerException)
+	a.Router.DELETE("/interviewer/:interviewer_id/exception/:exception_id", a.DeleteInterviewerException)
+
+	a.Router.GET("/interviewer/:interviewer_id/calendar.ics", a.GetInterviewerCalendar)
+	a.Router.POST("/interviewer/:interviewer_id/calendar_token", a.ResetInterviewerCalendarToken)
+	a.Router.POST("/interviewer/:interviewer_id/caldav_password", a.ResetCalDAVPassword)
+	a.Router.POST("/interviewer/:interviewer_id/import", a.ImportInterviewerCalendar)
+
+	a.Router.GET("/.well-known/caldav", a.CalDAVWellKnown)
+	a.Router.Handle("PROPFIND", "/.well-known/caldav", a.CalDAVWellKnown)
+	for _, path := range []string{"/caldav/interviewer/:interviewer_id/", "/caldav/interviewer/:interviewer_id/calendar/", "/caldav/interviewer/:interviewer_id/calendar/:resource"} {
+		a.Router.OPTIONS(path, a.CalDAVOptions)
+		a.Router.Handle("PROPFIND", path, a.CalDAVPropfind)
+	}
+	a.Router.Handle("REPORT", "/caldav/interviewer/:interviewer_id/calendar/", a.CalDAVReport)
+	a.Router.GET("/caldav/interviewer/:interviewer_id/calendar/:resource", a.CalDAVGet)
+	a.Router.HEAD("/caldav/interviewer/:interviewer_id/calendar/:resource", a.CalDAVGet)
+	a.Router.PUT("/caldav/interviewer/:interviewer_id/calendar/:resource", a.CalDAVPut)
+	a.Router.DELETE("/caldav/interviewer/:interviewer_id/calendar/:resource", a.CalDAVDelete)
+
 	a.Router.POST("/slot", a.SlotMatching)
+	a.Router.POST("/loop", a.LoopMatching)
+	a.Router.GET("/freebusy", a.GetFreeBusy)
+
+	a.Router.GET("/interview", a.GetAllInterviews)
+	a.Router.POST("/interview", a.AddInterview)
+	a.Router.GET("/interview/:interview_id", a.GetInterview)
+	a.Router.PATCH("/interview/:interview_id", a.UpdateInterviewStatus)
+	a.Router.DELETE("/interview/:interview_id", a.CancelInterview)
+	a.Router.POST("/interview/:interview_id/reschedule", a.RescheduleInterview)
+
+	a.Router.GET("/hold", a.GetAllHolds)
+	a.Router.POST("/hold", a.AddHold)
+	a.Router.GET("/hold/:hold_id", a.GetHold)
+	a.Router.DELETE("/hold/:hold_id", a.ReleaseHold)
 }
 
 /* CANDIDATES */
 func (a *App) AddCandidate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.AddCandidate(a.DB, w, r, ps)
+	routes.AddCandidate(a.Store, w, r, ps)
 }
 func (a *App) GetCandidate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetCandidate(a.DB, w, r, ps)
+	routes.GetCandidate(a.Store, w, r, ps)
 }
 func (a *App) GetAllCandidates(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetAllCandidates(a.DB, w, r, ps)
+	routes.GetAllCandidates(a.Store, w, r, ps)
 }
 func (a *App) UpdateCandidate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.UpdateCandidate(a.DB, w, r, ps)
+	routes.UpdateCandidate(a.Store, w, r, ps)
 }
 func (a *App) DeleteCandidate(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.DeleteCandidate(a.DB, w, r, ps)
+	routes.DeleteCandidate(a.Store, w, r, ps)
 }
 
 /* CANDIDATES */
 /* CANDIDATES SLOTS */
 func (a *App) AddCandidateSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.AddCandidateSlot(a.DB, w, r, ps)
+	routes.AddCandidateSlot(a.Store, w, r, ps)
 }
 func (a *App) GetCandidateSlots(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetCandidateSlots(a.DB, w, r, ps)
+	routes.GetCandidateSlots(a.Store, w, r, ps)
 }
 func (a *App) UpdateCandidateSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.UpdateCandidateSlot(a.DB, w, r, ps)
+	routes.UpdateCandidateSlot(a.Store, w, r, ps)
 }
 func (a *App) DeleteCandidateSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.DeleteCandidateSlot(a.DB, w, r, ps)
+	routes.DeleteCandidateSlot(a.Store, w, r, ps)
 }
 
 /* CANDIDATES SLOTS */
+/* CANDIDATES EXCEPTIONS */
+func (a *App) AddCandidateException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.AddException(a.Store, store.CandidateOwner, w, r, ps)
+}
+func (a *App) GetCandidateExceptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetExceptions(a.Store, store.CandidateOwner, w, r, ps)
+}
+func (a *App) UpdateCandidateException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.UpdateException(a.Store, store.CandidateOwner, w, r, ps)
+}
+func (a *App) DeleteCandidateException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.DeleteException(a.Store, store.CandidateOwner, w, r, ps)
+}
+
+/* CANDIDATES EXCEPTIONS */
+/* CANDIDATES CALENDAR */
+func (a *App) GetCandidateCalendar(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetCalendar(a.Store, store.CandidateOwner, w, r, ps)
+}
+func (a *App) ResetCandidateCalendarToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ResetCalendarToken(a.Store, store.CandidateOwner, w, r, ps)
+}
+func (a *App) ImportCandidateCalendar(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ImportCalendar(a.Store, a.Config.Matching, store.CandidateOwner, w, r, ps)
+}
+
+/* CANDIDATES CALENDAR */
 /* INTERVIEWERS */
 func (a *App) AddInterviewer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.AddInterviewer(a.DB, w, r, ps)
+	routes.AddInterviewer(a.Store, w, r, ps)
 }
 func (a *App) GetInterviewer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetCandidate(a.DB, w, r, ps)
+	routes.GetInterviewer(a.Store, w, r, ps)
 }
 func (a *App) GetAllInterviewers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetAllInterviewers(a.DB, w, r, ps)
+	routes.GetAllInterviewers(a.Store, w, r, ps)
 }
 func (a *App) UpdateInterviewer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.UpdateInterviewer(a.DB, w, r, ps)
+	routes.UpdateInterviewer(a.Store, w, r, ps)
 }
 func (a *App) DeleteInterviewer(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.DeleteInterviewer(a.DB, w, r, ps)
+	routes.DeleteInterviewer(a.Store, w, r, ps)
 }
 
 /* INTERVIEWERS */
 /* INTERVIEWERS SLOTS */
 func (a *App) AddInterviewerSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.AddInterviewerSlot(a.DB, w, r, ps)
+	routes.AddInterviewerSlot(a.Store, w, r, ps)
 }
 func (a *App) GetInterviewerSlots(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.GetInterviewerSlots(a.DB, w, r, ps)
+	routes.GetInterviewerSlots(a.Store, w, r, ps)
 }
 func (a *App) UpdateInterviewerSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.UpdateInterviewerSlot(a.DB, w, r, ps)
+	routes.UpdateInterviewerSlot(a.Store, w, r, ps)
 }
 func (a *App) DeleteInterviewerSlot(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.DeleteInterviewerSlot(a.DB, w, r, ps)
+	routes.DeleteInterviewerSlot(a.Store, w, r, ps)
 }
 
 /* INTERVIEWERS SLOTS */
+/* INTERVIEWERS EXCEPTIONS */
+func (a *App) AddInterviewerException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.AddException(a.Store, store.InterviewerOwner, w, r, ps)
+}
+func (a *App) GetInterviewerExceptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetExceptions(a.Store, store.InterviewerOwner, w, r, ps)
+}
+func (a *App) UpdateInterviewerException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.UpdateException(a.Store, store.InterviewerOwner, w, r, ps)
+}
+func (a *App) DeleteInterviewerException(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.DeleteException(a.Store, store.InterviewerOwner, w, r, ps)
+}
+
+/* INTERVIEWERS EXCEPTIONS */
+/* INTERVIEWERS CALENDAR */
+func (a *App) GetInterviewerCalendar(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetCalendar(a.Store, store.InterviewerOwner, w, r, ps)
+}
+func (a *App) ResetInterviewerCalendarToken(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ResetCalendarToken(a.Store, store.InterviewerOwner, w, r, ps)
+}
+func (a *App) ImportInterviewerCalendar(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ImportCalendar(a.Store, a.Config.Matching, store.InterviewerOwner, w, r, ps)
+}
+
+/* INTERVIEWERS CALENDAR */
+/* CALDAV */
+func (a *App) ResetCalDAVPassword(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ResetCalDAVPassword(a.Store, w, r, ps)
+}
+func (a *App) CalDAVWellKnown(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVWellKnown(w, r, ps)
+}
+func (a *App) CalDAVOptions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVOptions(w, r, ps)
+}
+func (a *App) CalDAVPropfind(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVPropfind(a.Store, w, r, ps)
+}
+func (a *App) CalDAVReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVReport(a.Store, w, r, ps)
+}
+func (a *App) CalDAVGet(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVGet(a.Store, w, r, ps)
+}
+func (a *App) CalDAVPut(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVPut(a.Store, w, r, ps)
+}
+func (a *App) CalDAVDelete(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CalDAVDelete(a.Store, w, r, ps)
+}
+
+/* CALDAV */
 /* SLOT MATCH */
 func (a *App) SlotMatching(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	routes.SlotMatching(a.DB, w, r, ps)
+	routes.SlotMatching(a.Store, a.Config.Matching, w, r, ps)
 }
 
 /* SLOT MATCH */
+/* LOOP MATCH */
+func (a *App) LoopMatching(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.LoopMatching(a.Store, a.Config.Matching, w, r, ps)
+}
+
+/* LOOP MATCH */
+/* FREEBUSY */
+func (a *App) GetFreeBusy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetFreeBusy(a.Store, a.Config.Matching, w, r, ps)
+}
+
+/* FREEBUSY */
+/* INTERVIEWS */
+func (a *App) AddInterview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.AddInterview(a.Store, w, r, ps)
+}
+func (a *App) GetInterview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetInterview(a.Store, w, r, ps)
+}
+func (a *App) GetAllInterviews(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetAllInterviews(a.Store, w, r, ps)
+}
+func (a *App) UpdateInterviewStatus(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.UpdateInterviewStatus(a.Store, w, r, ps)
+}
+func (a *App) CancelInterview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.CancelInterview(a.Store, w, r, ps)
+}
+func (a *App) RescheduleInterview(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.RescheduleInterview(a.Store, a.Config.Matching, w, r, ps)
+}
+
+/* INTERVIEWS */
+/* HOLDS */
+func (a *App) AddHold(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.AddHold(a.Store, a.Config.Holds, w, r, ps)
+}
+func (a *App) GetHold(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetHold(a.Store, w, r, ps)
+}
+func (a *App) GetAllHolds(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.GetAllHolds(a.Store, w, r, ps)
+}
+func (a *App) ReleaseHold(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	routes.ReleaseHold(a.Store, w, r, ps)
+}
+
+/* HOLDS */
+
+func (a *App) setLogging() {
+	if a.Config.LogLevel == "off" {
+		log.SetOutput(ioutil.Discard)
+	}
+}
 
 // Setting database
-func (a *App) setDatabase(config *config.Config) {
+func (a *App) setDatabase() error {
+	config := a.Config.DB
+	if config.Driver == "memory" {
+		return nil
+	}
 	var err error
-	dbDSN := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s",
-		config.DB.User,
-		config.DB.Pass,
-		config.DB.Host,
-		config.DB.Port,
-		config.DB.Name,
-		config.DB.Charset)
-
-	a.DB, err = sql.Open(config.DB.Driver, dbDSN)
+	var dbDSN, target string
+	switch config.Driver {
+	case "sqlite3":
+		dbDSN = config.File + "?_foreign_keys=on&_txlock=immediate"
+		target = config.File
+	case "postgres":
+		dbDSN = fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			config.Host,
+			config.Port,
+			config.User,
+			config.Pass,
+			config.Name,
+			config.SSLMode)
+		target = fmt.Sprintf("%s@%s:%s/%s", config.User, config.Host, config.Port, config.Name)
+	default:
+		dbDSN = fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=%s",
+			config.User,
+			config.Pass,
+			config.Host,
+			config.Port,
+			config.Name,
+			config.Charset)
+		target = fmt.Sprintf("%s@%s:%s/%s", config.User, config.Host, config.Port, config.Name)
+	}
+
+	a.DB, err = sql.Open(config.Driver, dbDSN)
 	if err != nil {
-		log.Fatal("Could not connect database")
+		return fmt.Errorf("could not open %s database %s: %v", config.Driver, target, err)
+	}
+	if config.Driver == "sqlite3" {
+		// SQLite allows a single writer, queue the queries instead of
+		// failing with "database is locked"
+		a.DB.SetMaxOpenConns(1)
+	}
+	if err = a.DB.Ping(); err != nil {
+		return fmt.Errorf("could not reach %s database %s: %v", config.Driver, target, err)
 	}
-	err = a.DB.Ping()
+	return nil
+}
+
+func (a *App) setStores() error {
+	if a.DB == nil {
+		a.Store = store.NewMemory()
+		return nil
+	}
+	dialect, err := store.NewDialect(a.Config.DB.Driver)
+	if err != nil {
+		return err
+	}
+	if a.Config.DB.Migrate {
+		migrator, err := migrations.New(a.DB, dialect.Name())
+		if err != nil {
+			return err
+		}
+		if err = migrateUp(migrator); err != nil {
+			return err
+		}
+	}
+	a.Store = store.NewSQL(a.DB, dialect)
+	return nil
+}
+
+// Migrate runs the "migrate up", "migrate down" or "migrate status"
+// command against the configured database
+func (a *App) Migrate(config *config.Config, command string) error {
+	a.Config = config
+	a.setLogging()
+	if err := a.setDatabase(); err != nil {
+		return err
+	}
+	if a.DB == nil {
+		return errors.New("the memory driver has no schema to migrate")
+	}
+	migrator, err := migrations.New(a.DB, config.DB.Driver)
 	if err != nil {
-		log.Fatal("Could not ping database")
+		return err
+	}
+
+	switch command {
+	case "up":
+		return migrateUp(migrator)
+	case "down":
+		migration, err := migrator.Down()
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			log.Println("No migration to revert")
+			return nil
+		}
+		log.Printf("Reverted migration %04d_%s", migration.Version, migration.Name)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied {
+				applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, use up, down or status", command)
+	}
+	return nil
+}
+
+func migrateUp(migrator *migrations.Migrator) error {
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+func (a *App) Run() error {
+	var handler http.Handler = a.Router
+	if a.Config.LogLevel == "debug" {
+		handler = logRequests(handler)
+	}
+	server := &http.Server{
+		Addr:         a.Config.Server.Addr,
+		Handler:      handler,
+		ReadTimeout:  a.Config.Server.ReadTimeout,
+		WriteTimeout: a.Config.Server.WriteTimeout,
+		IdleTimeout:  a.Config.Server.IdleTimeout,
+	}
+	go a.expireHolds()
+	log.Println("Listening on", server.Addr)
+	return server.ListenAndServe()
+}
+
+// Deletes the expired holds every holds.expiry_interval. The routes already
+// ignore them, this keeps the holds tables small.
+func (a *App) expireHolds() {
+	ticker := time.NewTicker(a.Config.Holds.ExpiryInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		expired, err := a.Store.DeleteExpiredHolds(now)
+		if err != nil {
+			log.Println("Hold Expiry Error ::", err.Error())
+			continue
+		}
+		if expired > 0 {
+			log.Printf("Expired %d holds", expired)
+		}
 	}
 }
 
-func (a *App) Run(host string) {
-	log.Fatal(http.ListenAndServe(host, a.Router))
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		log.Println(r.Method, r.URL.Path, time.Since(start))
+	})
 }
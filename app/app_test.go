@@ -0,0 +1,755 @@
+package app
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+// An API on the memory store, without logs
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	c := config.Default()
+	c.DB.Driver = "memory"
+	c.LogLevel = "off"
+	a := &App{}
+	if err := a.Initialize(c); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+// Sends the request and checks its status, decoding the response into
+// result when given
+func call(t *testing.T, a *App, method, path, body string, status int, result interface{}) {
+	t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	a.Router.ServeHTTP(recorder, request)
+	if recorder.Code != status {
+		t.Fatalf("%s %s answered %d, want %d: %s", method, path, recorder.Code, status, recorder.Body.String())
+	}
+	if result != nil {
+		if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+			t.Fatalf("%s %s answered %q: %v", method, path, recorder.Body.String(), err)
+		}
+	}
+}
+
+// Tomorrow in UTC, so the booked times are in the future and within the
+// next seven days of the weekly matching
+func tomorrow() model.Date {
+	return model.DateOf(time.Now().UTC()).AddDays(1)
+}
+
+func at(day model.Date, hour int) string {
+	return time.Date(day.Year, day.Month, day.Day, hour, 0, 0, 0, time.UTC).Format(time.RFC3339)
+}
+
+// A candidate and an interviewer available from 09:00 to 12:00 UTC every day
+func addPeople(t *testing.T, a *App) {
+	t.Helper()
+	everyDay := `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[0,1,2,3,4,5,6]}`
+	call(t, a, "POST", "/candidate", `{"Name":"Carl"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/candidate/1/slot", everyDay, http.StatusOK, nil)
+	call(t, a, "POST", "/interviewer", `{"Name":"Ingrid"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/interviewer/1/slot", everyDay, http.StatusOK, nil)
+}
+
+func TestPeople(t *testing.T) {
+	for _, owner := range []string{"candidate", "interviewer"} {
+		t.Run(owner, func(t *testing.T) {
+			a := newTestApp(t)
+			call(t, a, "GET", "/"+owner, "", http.StatusNoContent, nil)
+
+			var added model.Candidate
+			call(t, a, "POST", "/"+owner, `{"Name":"Alex","TimeZone":"Europe/Berlin"}`, http.StatusOK, &added)
+			if added.Id != 1 || added.Name != "Alex" || added.TimeZone != "Europe/Berlin" {
+				t.Errorf("added %+v", added)
+			}
+			call(t, a, "POST", "/"+owner, `{"Name":"Sam"}`, http.StatusOK, &added)
+			if added.Id != 2 || added.TimeZone != model.DefaultTimeZone {
+				t.Errorf("added %+v, want Id 2 in the default time zone", added)
+			}
+			call(t, a, "POST", "/"+owner, `{"Name":"Mars","TimeZone":"Mars/Olympus"}`, http.StatusBadRequest, nil)
+			call(t, a, "POST", "/"+owner, `{`, http.StatusBadRequest, nil)
+
+			var all []model.Candidate
+			call(t, a, "GET", "/"+owner, "", http.StatusOK, &all)
+			if len(all) != 2 || all[0].Name != "Alex" || all[1].Name != "Sam" {
+				t.Errorf("listed %+v", all)
+			}
+
+			var updated model.Candidate
+			call(t, a, "PUT", "/"+owner+"/1", `{"Name":"Alexandra"}`, http.StatusOK, &updated)
+			if updated.Name != "Alexandra" || updated.TimeZone != "Europe/Berlin" {
+				t.Errorf("updated %+v, want the time zone kept", updated)
+			}
+			var read model.Candidate
+			call(t, a, "GET", "/"+owner+"/1", "", http.StatusOK, &read)
+			if read != updated {
+				t.Errorf("read %+v, want %+v", read, updated)
+			}
+
+			call(t, a, "DELETE", "/"+owner+"/1", "", http.StatusOK, nil)
+			call(t, a, "GET", "/"+owner+"/1", "", http.StatusNotFound, nil)
+			call(t, a, "PUT", "/"+owner+"/9", `{"Name":"Nobody"}`, http.StatusNotFound, nil)
+		})
+	}
+}
+
+func TestSlots(t *testing.T) {
+	for _, owner := range []string{"candidate", "interviewer"} {
+		t.Run(owner, func(t *testing.T) {
+			a := newTestApp(t)
+			call(t, a, "POST", "/"+owner, `{"Name":"Alex"}`, http.StatusOK, nil)
+
+			var slot model.Slot
+			call(t, a, "POST", "/"+owner+"/1/slot", `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[1,3]}`, http.StatusOK, &slot)
+			if slot.Id != 1 || slot.PersonId != 1 || slot.InitialTime.String() != "09:00:00" || len(slot.Weekdays) != 2 {
+				t.Errorf("added %+v", slot)
+			}
+			call(t, a, "POST", "/"+owner+"/1/slot", `{"InitialTime":"12:00","FinalTime":"09:00","Weekdays":[1]}`, http.StatusBadRequest, nil)
+			call(t, a, "POST", "/"+owner+"/1/slot", `{"InitialTime":"9h","FinalTime":"12:00","Weekdays":[1]}`, http.StatusBadRequest, nil)
+
+			call(t, a, "PUT", "/"+owner+"/1/slot/1", `{"InitialTime":"10:00","FinalTime":"11:00","Weekdays":[5]}`, http.StatusOK, &slot)
+			var slots []model.Slot
+			call(t, a, "GET", "/"+owner+"/1/slot", "", http.StatusOK, &slots)
+			if len(slots) != 1 || slots[0].InitialTime.String() != "10:00:00" || len(slots[0].Weekdays) != 1 || slots[0].Weekdays[0] != time.Friday {
+				t.Errorf("listed %+v", slots)
+			}
+
+			call(t, a, "DELETE", "/"+owner+"/1/slot/1", "", http.StatusOK, nil)
+			call(t, a, "GET", "/"+owner+"/1/slot", "", http.StatusOK, &slots)
+			if len(slots) != 0 {
+				t.Errorf("listed %+v after the deletion", slots)
+			}
+		})
+	}
+}
+
+func TestSlotMatching(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+
+	// The starts are on the quarter hour whatever the duration
+	var weekly []model.Slot
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Duration":"45m"}`, http.StatusOK, &weekly)
+	if len(weekly) != 7*10 {
+		t.Errorf("got %d weekly slots, want 10 a day", len(weekly))
+	}
+	if len(weekly) > 0 && (weekly[0].InitialTime.String() != "09:00:00" || weekly[0].FinalTime.String() != "09:45:00") {
+		t.Errorf("first weekly slot %+v, want 09:00 to 09:45", weekly[0])
+	}
+
+	var options []model.InterviewOption
+	body := fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Duration":"90m","Step":"90m","From":"%s","To":"%s"}`, day, day)
+	call(t, a, "POST", "/slot", body, http.StatusOK, &options)
+	if len(options) != 2 || options[0].Start.Format(time.RFC3339) != at(day, 9) {
+		t.Errorf("got %+v, want the 09:00 and 10:30 options of %s", options, day)
+	}
+
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Duration":"forever"}`, http.StatusBadRequest, nil)
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"TimeZone":"Mars/Olympus"}`, http.StatusBadRequest, nil)
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":9},"Interviewers":[{"Id":1}]}`, http.StatusNotFound, nil)
+}
+
+func TestSlotMatchingLengths(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+
+	// Durations and steps are whole minutes, whatever the endpoint
+	for _, lengths := range []string{`"Step":"1ns"`, `"Step":"90s"`, `"Step":"0m"`, `"Step":"-15m"`, `"Duration":"1ns"`, `"Duration":"30m30s"`} {
+		body := fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],%s}`, lengths)
+		call(t, a, "POST", "/slot", body, http.StatusBadRequest, nil)
+		dated := fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"From":"%s",%s}`, day, lengths)
+		call(t, a, "POST", "/slot", dated, http.StatusBadRequest, nil)
+		loop := fmt.Sprintf(`{"Candidate":{"Id":1},"Rounds":[{"Interviewers":[{"Id":1}]}],%s}`, lengths)
+		call(t, a, "POST", "/loop", loop, http.StatusBadRequest, nil)
+	}
+	call(t, a, "POST", "/loop", `{"Candidate":{"Id":1},"Rounds":[{"Interviewers":[{"Id":1}],"Duration":"1ns"}]}`, http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", `{"Candidate":{"Id":1},"Rounds":[{"Interviewers":[{"Id":1}],"Duration":"45m"}],"Step":"5m"}`, http.StatusOK, nil)
+
+	// A minute step over a whole year answers the first options only
+	var options []model.InterviewOption
+	body := fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Duration":"1m","Step":"1m","From":"%s","To":"%s"}`, day, day.AddDays(365))
+	call(t, a, "POST", "/slot", body, http.StatusOK, &options)
+	if len(options) != 1000 || options[0].Start.Format(time.RFC3339) != at(day, 9) {
+		t.Errorf("got %d options from %v, want the first 1000", len(options), options[0].Start)
+	}
+	var weekly []model.Slot
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Duration":"1m","Step":"1m"}`, http.StatusOK, &weekly)
+	if len(weekly) != 1000 {
+		t.Errorf("got %d weekly slots, want the first 1000", len(weekly))
+	}
+}
+
+func TestSlotMatchingExceptions(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	body := fmt.Sprintf(`{"From":"%s","InitialTime":"09:00","To":"%s","FinalTime":"12:00","Reason":"Dentist"}`, day, day)
+	call(t, a, "POST", "/interviewer/1/exception", body, http.StatusOK, nil)
+
+	var weekly []model.Slot
+	call(t, a, "POST", "/slot", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}]}`, http.StatusOK, &weekly)
+	for _, slot := range weekly {
+		if slot.Weekdays[0] == day.Weekday() {
+			t.Errorf("weekly slot %+v offered on the day of the exception", slot)
+		}
+	}
+	var options []model.InterviewOption
+	call(t, a, "POST", "/slot", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"From":"%s","To":"%s"}`, day, day), http.StatusOK, &options)
+	if len(options) != 0 {
+		t.Errorf("got %+v during the exception", options)
+	}
+}
+
+// The starts of the hour long weekly slots on the weekday of day
+func weeklyStarts(t *testing.T, a *App, candidate int, day model.Date) []string {
+	t.Helper()
+	var weekly []model.Slot
+	call(t, a, "POST", "/slot", fmt.Sprintf(`{"Candidate":{"Id":%d},"Interviewers":[{"Id":1}],"Duration":"1h"}`, candidate), http.StatusOK, &weekly)
+	starts := []string{}
+	for _, slot := range weekly {
+		if slot.Weekdays[0] == day.Weekday() {
+			starts = append(starts, slot.InitialTime.String()[:5])
+		}
+	}
+	return starts
+}
+
+func TestSlotMatchingInterviews(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	var booking model.Booking
+	call(t, a, "POST", "/interview", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 9), at(day, 10)), http.StatusOK, &booking)
+
+	want := []string{"10:00", "10:15", "10:30", "10:45", "11:00"}
+	if starts := weeklyStarts(t, a, 1, day); !reflect.DeepEqual(starts, want) {
+		t.Errorf("weekly starts %v on the day of the interview, want %v", starts, want)
+	}
+	call(t, a, "DELETE", fmt.Sprintf("/interview/%d", booking.Id), "", http.StatusOK, nil)
+	want = []string{"09:00", "09:15", "09:30", "09:45", "10:00", "10:15", "10:30", "10:45", "11:00"}
+	if starts := weeklyStarts(t, a, 1, day); !reflect.DeepEqual(starts, want) {
+		t.Errorf("weekly starts %v once the interview is cancelled, want %v", starts, want)
+	}
+}
+
+func TestSlotMatchingHolds(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	call(t, a, "POST", "/candidate", `{"Name":"Cora"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/candidate/2/slot", `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[0,1,2,3,4,5,6]}`, http.StatusOK, nil)
+	day := tomorrow()
+	call(t, a, "POST", "/hold", fmt.Sprintf(`{"Candidate":{"Id":2},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 9), at(day, 10)), http.StatusOK, nil)
+
+	// The other candidates can't have the held time, the held candidate can
+	want := []string{"10:00", "10:15", "10:30", "10:45", "11:00"}
+	if starts := weeklyStarts(t, a, 1, day); !reflect.DeepEqual(starts, want) {
+		t.Errorf("weekly starts %v on the day of the hold, want %v", starts, want)
+	}
+	want = []string{"09:00", "09:15", "09:30", "09:45", "10:00", "10:15", "10:30", "10:45", "11:00"}
+	if starts := weeklyStarts(t, a, 2, day); !reflect.DeepEqual(starts, want) {
+		t.Errorf("weekly starts %v for the held candidate, want %v", starts, want)
+	}
+	call(t, a, "DELETE", "/hold/1", "", http.StatusOK, nil)
+	if starts := weeklyStarts(t, a, 1, day); !reflect.DeepEqual(starts, want) {
+		t.Errorf("weekly starts %v once the hold is released, want %v", starts, want)
+	}
+}
+
+func TestLoopMatching(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	call(t, a, "POST", "/interviewer", `{"Name":"Ian"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/interviewer/2/slot", `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[0,1,2,3,4,5,6]}`, http.StatusOK, nil)
+	day := tomorrow()
+	loop := func(rounds string) string {
+		return fmt.Sprintf(`{"Candidate":{"Id":1},"From":"%s","To":"%s","Step":"30m","Rounds":%s}`, day, day, rounds)
+	}
+
+	// Ingrid leads the first round, so Ian the second one, after the break
+	var itineraries []model.Itinerary
+	call(t, a, "POST", "/loop", loop(`[
+		{"Name":"Technical","Interviewers":[{"Id":1}]},
+		{"Name":"Culture","Interviewers":[{"Id":1},{"Id":2}],"Duration":"30m","Break":"30m"}]`), http.StatusOK, &itineraries)
+	want := [][]string{
+		{"Technical 1 09:00-10:00", "Culture 2 10:30-11:00"},
+		{"Technical 1 09:30-10:30", "Culture 2 11:00-11:30"},
+		{"Technical 1 10:00-11:00", "Culture 2 11:30-12:00"},
+	}
+	got := [][]string{}
+	for _, itinerary := range itineraries {
+		rounds := []string{}
+		for _, round := range itinerary.Rounds {
+			rounds = append(rounds, fmt.Sprintf("%s %d %s-%s", round.Name, round.Interviewer.Id, round.Start.Format("15:04"), round.End.Format("15:04")))
+		}
+		if !itinerary.Start.Equal(itinerary.Rounds[0].Start) || !itinerary.End.Equal(itinerary.Rounds[len(rounds)-1].End) {
+			t.Errorf("itinerary from %v to %v, want the times of its rounds", itinerary.Start, itinerary.End)
+		}
+		got = append(got, rounds)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("itineraries %v, want %v", got, want)
+	}
+
+	call(t, a, "POST", "/loop", loop(`[{"Interviewers":[{"Id":1}],"Duration":"90s"}]`), http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", loop(`[{"Interviewers":[{"Id":1}],"Break":"-5m"}]`), http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", loop(`[{"Interviewers":[{"Id":1},{"Id":1}]}]`), http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", `{"Candidate":{"Id":1},"Interviewers":[{"Id":2}],"Rounds":[{"Interviewers":[{"Id":2}]}]}`, http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", loop(`[]`), http.StatusBadRequest, nil)
+	call(t, a, "POST", "/loop", loop(`[{"Interviewers":[{"Id":9}]}]`), http.StatusNotFound, nil)
+}
+
+func TestInterviews(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	interview := func(start, end int) string {
+		return fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, start), at(day, end))
+	}
+
+	var booking model.Booking
+	call(t, a, "POST", "/interview", interview(9, 10), http.StatusOK, &booking)
+	if booking.Id != 1 || booking.Status != model.BookingConfirmed {
+		t.Errorf("booked %+v", booking)
+	}
+	var conflict struct {
+		Error   string
+		Booking model.Booking
+	}
+	call(t, a, "POST", "/interview", interview(9, 11), http.StatusConflict, &conflict)
+	if conflict.Booking.Id != 1 {
+		t.Errorf("conflict %+v, want interview 1 in the way", conflict)
+	}
+	call(t, a, "POST", "/interview", interview(13, 14), http.StatusUnprocessableEntity, nil)
+	call(t, a, "POST", "/interview", `{"Candidate":{"Id":1},"Interviewers":[{"Id":1}]}`, http.StatusBadRequest, nil)
+
+	var options []model.InterviewOption
+	call(t, a, "POST", "/slot", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"From":"%s","To":"%s"}`, day, day), http.StatusOK, &options)
+	for _, option := range options {
+		if option.Start.Before(booking.End) && booking.Start.Before(option.End) {
+			t.Errorf("option %+v overlaps the interview", option)
+		}
+	}
+
+	call(t, a, "GET", "/interview/1", "", http.StatusOK, &booking)
+	if len(booking.History) != 1 {
+		t.Errorf("history %+v, want the booking", booking.History)
+	}
+	call(t, a, "PATCH", "/interview/1", `{"Status":"done"}`, http.StatusBadRequest, nil)
+	call(t, a, "DELETE", "/interview/1", "", http.StatusOK, &booking)
+	if booking.Status != model.BookingCancelled {
+		t.Errorf("cancelled %+v", booking)
+	}
+	call(t, a, "PATCH", "/interview/1", `{"Status":"confirmed"}`, http.StatusConflict, nil)
+	call(t, a, "GET", "/interview/9", "", http.StatusNotFound, nil)
+
+	// The cancelled interview doesn't take the time anymore
+	call(t, a, "POST", "/interview", interview(9, 10), http.StatusOK, &booking)
+	var all []model.Booking
+	call(t, a, "GET", "/interview?status=confirmed", "", http.StatusOK, &all)
+	if len(all) != 1 || all[0].Id != 2 {
+		t.Errorf("confirmed interviews %+v, want interview 2", all)
+	}
+}
+
+func TestRescheduleInterview(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	next := day.AddDays(1)
+	body := fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(next, 11), at(next, 12))
+	call(t, a, "POST", "/interview", body, http.StatusOK, nil)
+
+	// The closest alternatives first, without the current time
+	var alternatives []model.InterviewOption
+	body = fmt.Sprintf(`{"From":"%s","To":"%s","Step":"30m"}`, day, next)
+	call(t, a, "POST", "/interview/1/reschedule", body, http.StatusOK, &alternatives)
+	got := []string{}
+	for _, alternative := range alternatives {
+		got = append(got, alternative.Start.Format(time.RFC3339))
+		if alternative.End.Sub(alternative.Start) != time.Hour {
+			t.Errorf("alternative %+v doesn't last an hour like the interview", alternative)
+		}
+	}
+	want := []string{at(next, 10), next.In(time.UTC).Add(9*time.Hour + 30*time.Minute).Format(time.RFC3339), at(next, 9), at(day, 11)}
+	if len(got) != 8 || !reflect.DeepEqual(got[:4], want) {
+		t.Errorf("alternatives %v, want 8 starting with %v", got, want)
+	}
+	call(t, a, "POST", "/interview/1/reschedule", "", http.StatusOK, &alternatives)
+	call(t, a, "POST", "/interview/1/reschedule", `{"Step":"1ns"}`, http.StatusBadRequest, nil)
+	call(t, a, "POST", "/interview/9/reschedule", "", http.StatusNotFound, nil)
+
+	// Another candidate's interview with the same interviewer is in the way
+	call(t, a, "POST", "/candidate", `{"Name":"Cora"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/candidate/2/slot", `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[0,1,2,3,4,5,6]}`, http.StatusOK, nil)
+	body = fmt.Sprintf(`{"Candidate":{"Id":2},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 11), at(day, 12))
+	call(t, a, "POST", "/interview", body, http.StatusOK, nil)
+	call(t, a, "POST", "/interview/1/reschedule", fmt.Sprintf(`{"Start":"%s"}`, at(day, 11)), http.StatusConflict, nil)
+	call(t, a, "POST", "/interview/1/reschedule", fmt.Sprintf(`{"Start":"%s"}`, at(day, 13)), http.StatusUnprocessableEntity, nil)
+
+	// The move keeps the length and is recorded in the history
+	var moved model.Booking
+	call(t, a, "POST", "/interview/1/reschedule", fmt.Sprintf(`{"Start":"%s"}`, at(day, 9)), http.StatusOK, &moved)
+	if moved.Status != model.BookingRescheduled || moved.Start.Format(time.RFC3339) != at(day, 9) || moved.End.Format(time.RFC3339) != at(day, 10) {
+		t.Errorf("moved %+v, want rescheduled from 09:00 to 10:00", moved)
+	}
+	if len(moved.History) != 2 || moved.History[1].Status != model.BookingRescheduled || moved.History[1].PreviousStatus != model.BookingConfirmed || !moved.History[1].Start.Equal(moved.Start) {
+		t.Errorf("history %+v, want the booking then the move", moved.History)
+	}
+	call(t, a, "DELETE", "/interview/1", "", http.StatusOK, nil)
+	call(t, a, "POST", "/interview/1/reschedule", "", http.StatusConflict, nil)
+}
+
+func TestFreeBusy(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	call(t, a, "POST", "/interview", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 9), at(day, 10)), http.StatusOK, nil)
+	call(t, a, "POST", "/hold", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 11), at(day, 12)), http.StatusOK, nil)
+	path := fmt.Sprintf("/freebusy?candidates=1&interviewers=1&from=%s&to=%s", day, day)
+
+	// Every hold keeps the interviewer busy, even the one of the candidate
+	var response model.FreeBusyResponse
+	call(t, a, "GET", path, "", http.StatusOK, &response)
+	hours := func(periods []model.Period) []string {
+		formatted := []string{}
+		for _, period := range periods {
+			formatted = append(formatted, period.Start.Format("15:04")+"-"+period.End.Format("15:04"))
+		}
+		return formatted
+	}
+	if len(response.Candidates) != 1 || len(response.Interviewers) != 1 || response.From != day || response.TimeZone != "UTC" {
+		t.Fatalf("free/busy %+v", response)
+	}
+	for _, test := range []struct {
+		person                  model.FreeBusy
+		free, busy, unavailable []string
+	}{
+		{response.Candidates[0], []string{"10:00-12:00"}, []string{"09:00-10:00"}, []string{"00:00-09:00", "12:00-00:00"}},
+		{response.Interviewers[0], []string{"10:00-11:00"}, []string{"09:00-10:00", "11:00-12:00"}, []string{"00:00-09:00", "12:00-00:00"}},
+	} {
+		if got := hours(test.person.Free); !reflect.DeepEqual(got, test.free) {
+			t.Errorf("%s free %v, want %v", test.person.Name, got, test.free)
+		}
+		if got := hours(test.person.Busy); !reflect.DeepEqual(got, test.busy) {
+			t.Errorf("%s busy %v, want %v", test.person.Name, got, test.busy)
+		}
+		if got := hours(test.person.Unavailable); !reflect.DeepEqual(got, test.unavailable) {
+			t.Errorf("%s unavailable %v, want %v", test.person.Name, got, test.unavailable)
+		}
+	}
+
+	// The same periods as VFREEBUSY components, asked by format or Accept
+	for _, header := range []string{"", "text/calendar"} {
+		icsPath := path + "&format=ics"
+		if header != "" {
+			icsPath = path
+		}
+		request := httptest.NewRequest("GET", icsPath, nil)
+		request.Header.Set("Accept", header)
+		recorder := httptest.NewRecorder()
+		a.Router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK || !strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/calendar") {
+			t.Fatalf("GET %s answered %d %q", icsPath, recorder.Code, recorder.Header().Get("Content-Type"))
+		}
+		calendar, err := ical.Decode(recorder.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(calendar.Components) != 2 || calendar.Components[1].Name != "VFREEBUSY" {
+			t.Fatalf("components %+v, want a VFREEBUSY per person", calendar.Components)
+		}
+		interviewer := calendar.Components[1]
+		if attendee := interviewer.Property("ATTENDEE"); attendee == nil || attendee.Params["CN"] != "Ingrid" {
+			t.Errorf("ATTENDEE %+v, want Ingrid", attendee)
+		}
+		busy := []string{}
+		for _, property := range interviewer.All("FREEBUSY") {
+			if property.Params["FBTYPE"] != "BUSY" {
+				continue
+			}
+			periods, err := property.Periods()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, period := range periods {
+				busy = append(busy, period.Start.Format("15:04")+"-"+period.End.Format("15:04"))
+			}
+		}
+		if want := []string{"09:00-10:00", "11:00-12:00"}; !reflect.DeepEqual(busy, want) {
+			t.Errorf("busy FREEBUSY %v, want %v", busy, want)
+		}
+	}
+
+	call(t, a, "GET", "/freebusy?interviewers=1,x", "", http.StatusBadRequest, nil)
+	call(t, a, "GET", "/freebusy?interviewers=0", "", http.StatusBadRequest, nil)
+	call(t, a, "GET", "/freebusy?from="+day.String(), "", http.StatusBadRequest, nil)
+	call(t, a, "GET", fmt.Sprintf("/freebusy?candidates=1&from=%s&to=%s", day, day.AddDays(-1)), "", http.StatusBadRequest, nil)
+	call(t, a, "GET", "/freebusy?candidates=1&timezone=Nowhere/City", "", http.StatusBadRequest, nil)
+	call(t, a, "GET", "/freebusy?interviewers=9", "", http.StatusNotFound, nil)
+}
+
+func TestCalendarFeed(t *testing.T) {
+	a := newTestApp(t)
+	call(t, a, "POST", "/interviewer", `{"Name":"Ingrid","TimeZone":"Europe/Berlin"}`, http.StatusOK, nil)
+	call(t, a, "POST", "/interviewer/1/slot", `{"InitialTime":"09:00","FinalTime":"12:00","Weekdays":[3,1]}`, http.StatusOK, nil)
+	var token map[string]string
+	call(t, a, "POST", "/interviewer/1/calendar_token", "", http.StatusOK, &token)
+
+	request := httptest.NewRequest("GET", "/interviewer/1/calendar.ics?token="+token["Token"], nil)
+	recorder := httptest.NewRecorder()
+	a.Router.ServeHTTP(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("GET calendar.ics answered %d: %s", recorder.Code, recorder.Body.String())
+	}
+	// The weekly slot starts on the same date whatever the current week
+	feed := recorder.Body.String()
+	for _, line := range []string{
+		"TZID:Europe/Berlin",
+		"DTSTART;TZID=Europe/Berlin:20240101T090000",
+		"DTEND;TZID=Europe/Berlin:20240101T120000",
+		"RRULE:FREQ=WEEKLY;BYDAY=WE,MO",
+	} {
+		if !strings.Contains(feed, line+"\r\n") {
+			t.Errorf("no %s in the feed\n%s", line, feed)
+		}
+	}
+}
+
+// Sends a CalDAV request as interviewer 1 and checks its status
+func davCall(t *testing.T, a *App, method, resource, token, body string, header http.Header, status int) *httptest.ResponseRecorder {
+	t.Helper()
+	request := httptest.NewRequest(method, "/caldav/interviewer/1/calendar/"+resource, strings.NewReader(body))
+	request.SetBasicAuth("1", token)
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	a.Router.ServeHTTP(recorder, request)
+	if recorder.Code != status {
+		t.Fatalf("%s %s answered %d, want %d: %s", method, resource, recorder.Code, status, recorder.Body.String())
+	}
+	return recorder
+}
+
+// A weekly event from 14:00 to 15:00 UTC on day, with the EXDATEs
+func davEvent(uid string, day model.Date, exdates ...model.Date) string {
+	start := fmt.Sprintf("%04d%02d%02dT14", day.Year, day.Month, day.Day)
+	lines := []string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Test//EN", "BEGIN:VEVENT",
+		"UID:" + uid, "DTSTAMP:20261001T000000Z", "DTSTART:" + start + "0000Z", "DTEND:" + start + "5900Z", "RRULE:FREQ=WEEKLY"}
+	for _, exdate := range exdates {
+		lines = append(lines, fmt.Sprintf("EXDATE:%04d%02d%02dT140000Z", exdate.Year, exdate.Month, exdate.Day))
+	}
+	lines = append(lines, "SUMMARY:Office hours", "TRANSP:TRANSPARENT", "END:VEVENT", "END:VCALENDAR", "")
+	return strings.Join(lines, "\r\n")
+}
+
+func TestCalDAVPut(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	var credentials map[string]string
+	call(t, a, "POST", "/interviewer/1/caldav_password", "", http.StatusOK, &credentials)
+	if credentials["User"] != "1" || !strings.HasSuffix(credentials["URL"], "/caldav/interviewer/1/calendar/") {
+		t.Errorf("credentials %+v", credentials)
+	}
+	password := credentials["Password"]
+	day := tomorrow()
+
+	// The read-only feed token can't write
+	var token map[string]string
+	call(t, a, "POST", "/interviewer/1/calendar_token", "", http.StatusOK, &token)
+	davCall(t, a, "PUT", "other-name.ics", token["Token"], davEvent("abc-123", day), nil, http.StatusUnauthorized)
+
+	// The event stays under the name it was written to, whatever its UID
+	created := davCall(t, a, "PUT", "other-name.ics", password, davEvent("abc-123", day, day.AddDays(7)), http.Header{"If-None-Match": {"*"}}, http.StatusCreated)
+	etag := created.Header().Get("ETag")
+	if etag == "" {
+		t.Error("no ETag for the created event")
+	}
+	read := davCall(t, a, "GET", "other-name.ics", password, "", nil, http.StatusOK)
+	if read.Header().Get("ETag") != etag || !strings.Contains(read.Body.String(), "UID:abc-123") {
+		t.Errorf("read %s with ETag %s, want ETag %s", read.Body.String(), read.Header().Get("ETag"), etag)
+	}
+	davCall(t, a, "GET", "abc-123.ics", password, "", nil, http.StatusNotFound)
+	davCall(t, a, "PUT", "abc-123.ics", password, davEvent("abc-123", day), nil, http.StatusForbidden)
+
+	var exceptions []model.Exception
+	call(t, a, "GET", "/interviewer/1/exception", "", http.StatusOK, &exceptions)
+	if len(exceptions) != 1 || exceptions[0].From != day.AddDays(7) {
+		t.Errorf("exceptions %+v, want the EXDATE", exceptions)
+	}
+
+	// Updating replaces the exceptions, and answers with the new ETag
+	davCall(t, a, "PUT", "other-name.ics", password, davEvent("abc-123", day), http.Header{"If-Match": {`"stale"`}}, http.StatusPreconditionFailed)
+	updated := davCall(t, a, "PUT", "other-name.ics", password, davEvent("abc-123", day), http.Header{"If-Match": {etag}}, http.StatusNoContent)
+	if updated.Header().Get("ETag") == "" || updated.Header().Get("ETag") == etag {
+		t.Errorf("ETag %q after the update, want a new one", updated.Header().Get("ETag"))
+	}
+	call(t, a, "GET", "/interviewer/1/exception", "", http.StatusOK, &exceptions)
+	if len(exceptions) != 0 {
+		t.Errorf("exceptions %+v left after the EXDATE was removed", exceptions)
+	}
+	var slots []model.Slot
+	call(t, a, "GET", "/interviewer/1/slot", "", http.StatusOK, &slots)
+	if len(slots) != 2 || slots[1].Uid != "abc-123" || slots[1].FinalTime.String() != "14:59:00" {
+		t.Errorf("slots %+v, want the weekly slot and the event", slots)
+	}
+
+	davCall(t, a, "DELETE", "other-name.ics", password, "", nil, http.StatusNoContent)
+	call(t, a, "GET", "/interviewer/1/slot", "", http.StatusOK, &slots)
+	if len(slots) != 1 {
+		t.Errorf("slots %+v after the deletion, want the weekly slot", slots)
+	}
+}
+
+// The part of a multi-status the CalDAV tests look at
+type multistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Status   string `xml:"status"`
+		Propstat []struct {
+			Prop struct {
+				Inner string `xml:",innerxml"`
+			} `xml:"prop"`
+			Status string `xml:"status"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+func TestCalDAVPropfindReport(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	call(t, a, "POST", "/interview", fmt.Sprintf(`{"Candidate":{"Id":1},"Interviewers":[{"Id":1}],"Start":"%s","End":"%s"}`, at(day, 9), at(day, 10)), http.StatusOK, nil)
+	var credentials map[string]string
+	call(t, a, "POST", "/interviewer/1/caldav_password", "", http.StatusOK, &credentials)
+	password := credentials["Password"]
+	etag := davCall(t, a, "PUT", "office.ics", password, davEvent("office", day), nil, http.StatusCreated).Header().Get("ETag")
+
+	// The found properties of each href, or its own status, of the
+	// multi-status answering a request as interviewer 1
+	davMultistatus := func(method, path, depth, body string) map[string]string {
+		t.Helper()
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth("1", password)
+		request.Header.Set("Depth", depth)
+		recorder := httptest.NewRecorder()
+		a.Router.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusMultiStatus {
+			t.Fatalf("%s %s answered %d: %s", method, path, recorder.Code, recorder.Body.String())
+		}
+		var answer multistatus
+		if err := xml.Unmarshal(recorder.Body.Bytes(), &answer); err != nil {
+			t.Fatal(err)
+		}
+		responses := map[string]string{}
+		for _, response := range answer.Responses {
+			responses[response.Href] = response.Status
+			for _, propstat := range response.Propstat {
+				if strings.Contains(propstat.Status, " 200 ") {
+					responses[response.Href] += strings.Replace(propstat.Prop.Inner, "&#34;", `"`, -1)
+				}
+			}
+		}
+		return responses
+	}
+	hrefs := func(responses map[string]string) []string {
+		sorted := []string{}
+		for href := range responses {
+			sorted = append(sorted, strings.TrimPrefix(href, "/caldav/interviewer/1/"))
+		}
+		sort.Strings(sorted)
+		return sorted
+	}
+	props := `<?xml version="1.0"?><D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/><C:calendar-home-set/></D:prop></D:propfind>`
+
+	// The principal leads the clients to the calendar, which lists the
+	// weekly slot, the event and the interview
+	principal := davMultistatus("PROPFIND", "/caldav/interviewer/1/", "0", props)
+	if !reflect.DeepEqual(hrefs(principal), []string{""}) || !strings.Contains(principal["/caldav/interviewer/1/"], "<C:calendar-home-set><D:href>/caldav/interviewer/1/</D:href>") {
+		t.Errorf("principal %v", principal)
+	}
+	calendar := davMultistatus("PROPFIND", "/caldav/interviewer/1/calendar/", "1", props)
+	want := []string{"calendar/", "calendar/interview-1@kilabs-api.ics", "calendar/office.ics", "calendar/slot-2@kilabs-api.ics"}
+	if !reflect.DeepEqual(hrefs(calendar), want) {
+		t.Errorf("calendar hrefs %v, want %v", hrefs(calendar), want)
+	}
+	if found := calendar["/caldav/interviewer/1/calendar/office.ics"]; found != "<D:getetag>"+etag+"</D:getetag>" {
+		t.Errorf("event properties %q, want the ETag %s", found, etag)
+	}
+	if calendar = davMultistatus("PROPFIND", "/caldav/interviewer/1/calendar/", "0", props); !reflect.DeepEqual(hrefs(calendar), []string{"calendar/"}) {
+		t.Errorf("calendar hrefs %v at depth 0, want the calendar alone", hrefs(calendar))
+	}
+	davCall(t, a, "PROPFIND", "missing.ics", password, props, nil, http.StatusNotFound)
+
+	// Both reports answer with the events, the multiget with the ones asked
+	multiget := `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<D:href>/caldav/interviewer/1/calendar/office.ics</D:href><D:href>/caldav/interviewer/1/calendar/missing.ics</D:href></C:calendar-multiget>`
+	events := davMultistatus("REPORT", "/caldav/interviewer/1/calendar/", "1", multiget)
+	if !reflect.DeepEqual(hrefs(events), []string{"calendar/missing.ics", "calendar/office.ics"}) ||
+		!strings.Contains(events["/caldav/interviewer/1/calendar/office.ics"], "UID:office") ||
+		!strings.Contains(events["/caldav/interviewer/1/calendar/missing.ics"], " 404 ") {
+		t.Errorf("calendar-multiget %v", events)
+	}
+	query := `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VEVENT"/></C:comp-filter></C:filter></C:calendar-query>`
+	events = davMultistatus("REPORT", "/caldav/interviewer/1/calendar/", "1", query)
+	if !reflect.DeepEqual(hrefs(events), want[1:]) || !strings.Contains(events["/caldav/interviewer/1/calendar/interview-1@kilabs-api.ics"], "BEGIN:VEVENT") {
+		t.Errorf("calendar-query %v", events)
+	}
+	davCall(t, a, "REPORT", "", password, `<D:sync-collection xmlns:D="DAV:"><D:sync-token/></D:sync-collection>`, nil, http.StatusForbidden)
+
+	// The feed token only reads the feed
+	var token map[string]string
+	call(t, a, "POST", "/interviewer/1/calendar_token", "", http.StatusOK, &token)
+	davCall(t, a, "PROPFIND", "", token["Token"], props, nil, http.StatusUnauthorized)
+	davCall(t, a, "REPORT", "", token["Token"], query, nil, http.StatusUnauthorized)
+}
+
+func TestImportCalendar(t *testing.T) {
+	a := newTestApp(t)
+	addPeople(t, a)
+	day := tomorrow()
+	date := fmt.Sprintf("%04d%02d%02d", day.Year, day.Month, day.Day)
+	file := strings.Join([]string{"BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//Test//EN",
+		"BEGIN:VEVENT", "UID:free@example.com", "DTSTART:" + date + "T140000Z", "DTEND:" + date + "T160000Z", "TRANSP:TRANSPARENT", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:busy@example.com", "DTSTART:" + date + "T100000Z", "DTEND:" + date + "T110000Z", "SUMMARY:Dentist", "END:VEVENT",
+		"BEGIN:VEVENT", "UID:broken@example.com", "END:VEVENT",
+		"END:VCALENDAR", ""}, "\r\n")
+
+	var report model.ImportReport
+	call(t, a, "POST", "/interviewer/1/import", file, http.StatusOK, &report)
+	if len(report.Created) != 2 || report.Created[0].Slot == nil || report.Created[0].Slot.Id != 3 || report.Created[1].Exception == nil || len(report.Invalid) != 1 {
+		t.Errorf("report %+v, want the slot and the exception created", report)
+	}
+	var exceptions []model.Exception
+	call(t, a, "GET", "/interviewer/1/exception", "", http.StatusOK, &exceptions)
+	if len(exceptions) != 1 || exceptions[0].Reason != "Dentist" {
+		t.Errorf("exceptions %+v, want the busy event", exceptions)
+	}
+
+	// Importing the file again skips what it created
+	call(t, a, "POST", "/interviewer/1/import", file, http.StatusOK, &report)
+	if len(report.Created) != 0 || len(report.Skipped) != 2 {
+		t.Errorf("report %+v, want everything skipped", report)
+	}
+	call(t, a, "POST", "/interviewer/9/import", file, http.StatusNotFound, nil)
+	call(t, a, "POST", "/interviewer/1/import", "BEGIN:VEVENT", http.StatusBadRequest, nil)
+}
@@ -0,0 +1,179 @@
+// Package dav reads and writes the XML bodies of WebDAV (RFC 4918) requests
+// and responses, as used by CalDAV (RFC 4791): PROPFIND and REPORT requests
+// and their multi-status answers.
+package dav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// XML namespaces of WebDAV, CalDAV and the calendar server extensions
+const (
+	NS             = "DAV:"
+	CalDAV         = "urn:ietf:params:xml:ns:caldav"
+	CalendarServer = "http://calendarserver.org/ns/"
+)
+
+// Prefixes of the namespaces in the responses, to be used in the property
+// values
+var prefixes = map[string]string{NS: "D", CalDAV: "C", CalendarServer: "CS"}
+
+// Request is a PROPFIND or REPORT body: the Name of its root element, such
+// as calendar-multiget, the properties asked for and the hrefs listed.
+type Request struct {
+	Name     xml.Name
+	Props    []xml.Name
+	AllProps bool
+	Hrefs    []string
+}
+
+// ParseRequest reads the body of a PROPFIND or REPORT. An empty body asks
+// for all the properties, like an allprop.
+func ParseRequest(r io.Reader) (Request, error) {
+	request := Request{}
+	decoder := xml.NewDecoder(r)
+	// The path of the open elements
+	open := []xml.Name{}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return request, fmt.Errorf("dav: invalid XML: %v", err)
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			switch {
+			case len(open) == 0:
+				request.Name = token.Name
+			case token.Name == xml.Name{Space: NS, Local: "allprop"}:
+				request.AllProps = true
+			case open[len(open)-1] == xml.Name{Space: NS, Local: "prop"} && len(open) == 2:
+				request.Props = append(request.Props, token.Name)
+			case token.Name == xml.Name{Space: NS, Local: "href"} && len(open) == 1:
+				var href string
+				if err = decoder.DecodeElement(&href, &token); err != nil {
+					return request, fmt.Errorf("dav: invalid href: %v", err)
+				}
+				request.Hrefs = append(request.Hrefs, href)
+				continue
+			}
+			open = append(open, token.Name)
+		case xml.EndElement:
+			open = open[:len(open)-1]
+		}
+	}
+	if request.Name.Local == "" {
+		request.AllProps = true
+	}
+	return request, nil
+}
+
+// Property is a found property and its value, inner XML written as is. The
+// values use the D, C and CS prefixes of the DAV, CalDAV and CalendarServer
+// namespaces.
+type Property struct {
+	Name  xml.Name
+	Value string
+}
+
+// Response is the status of a resource in a multi-status: its found and
+// missing properties, or a Status of its own such as a 404.
+type Response struct {
+	Href     string
+	Found    []Property
+	NotFound []xml.Name
+	Status   int
+}
+
+// Text escapes a value for the inner XML of a property.
+func Text(value string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
+// Href is a DAV:href element, such as the value of current-user-principal.
+func Href(href string) string {
+	return "<D:href>" + Text(href) + "</D:href>"
+}
+
+// WriteMultistatus answers with a 207 listing the responses.
+func WriteMultistatus(w http.ResponseWriter, responses []Response) error {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	body.WriteString(`<D:multistatus` + namespaces() + `>`)
+	for _, response := range responses {
+		body.WriteString("<D:response>" + Href(response.Href))
+		if response.Status != 0 {
+			body.WriteString(status(response.Status))
+		}
+		if len(response.Found) > 0 {
+			body.WriteString("<D:propstat><D:prop>")
+			for _, property := range response.Found {
+				body.WriteString(element(property.Name, property.Value))
+			}
+			body.WriteString("</D:prop>" + status(http.StatusOK) + "</D:propstat>")
+		}
+		if len(response.NotFound) > 0 {
+			body.WriteString("<D:propstat><D:prop>")
+			for _, name := range response.NotFound {
+				body.WriteString(element(name, ""))
+			}
+			body.WriteString("</D:prop>" + status(http.StatusNotFound) + "</D:propstat>")
+		}
+		body.WriteString("</D:response>")
+	}
+	body.WriteString("</D:multistatus>")
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(http.StatusMultiStatus)
+	_, err := w.Write(body.Bytes())
+	return err
+}
+
+// WriteError answers with status and a DAV:error naming the precondition
+// that failed, such as CalDAV's valid-calendar-data.
+func WriteError(w http.ResponseWriter, status int, precondition xml.Name) error {
+	w.Header().Set("Content-Type", `application/xml; charset="utf-8"`)
+	w.WriteHeader(status)
+	_, err := io.WriteString(w, xml.Header+`<D:error`+namespaces()+`>`+element(precondition, "")+`</D:error>`)
+	return err
+}
+
+func namespaces() string {
+	spaces := []string{}
+	for space := range prefixes {
+		spaces = append(spaces, space)
+	}
+	sort.Strings(spaces)
+	declarations := ""
+	for _, space := range spaces {
+		declarations += fmt.Sprintf(` xmlns:%s="%s"`, prefixes[space], space)
+	}
+	return declarations
+}
+
+// An element with the prefix of its namespace, declaring the unknown ones
+func element(name xml.Name, value string) string {
+	tag, declaration := name.Local, ""
+	if prefix, ok := prefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+	} else if name.Space != "" {
+		tag = "x:" + name.Local
+		declaration = ` xmlns:x="` + Text(name.Space) + `"`
+	}
+	if value == "" {
+		return "<" + tag + declaration + "/>"
+	}
+	return "<" + tag + declaration + ">" + value + "</" + tag + ">"
+}
+
+func status(code int) string {
+	return fmt.Sprintf("<D:status>HTTP/1.1 %d %s</D:status>", code, http.StatusText(code))
+}
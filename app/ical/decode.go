@@ -0,0 +1,295 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is a FREEBUSY period, from Start to End.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+const dateLayout = "20060102"
+
+// Decode reads the first component of r, usually a VCALENDAR, with its
+// nested components. The lines are unfolded and the property names
+// upper-cased, the values are kept as is.
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var root *Component
+	stack := []*Component{}
+	for number, line := range lines {
+		if root != nil && len(stack) == 0 {
+			break
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		property, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("ical: line %d: %v", number+1, err)
+		}
+		switch property.Name {
+		case "BEGIN":
+			component := &Component{Name: strings.ToUpper(property.Value)}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.Components = append(parent.Components, component)
+			} else {
+				root = component
+			}
+			stack = append(stack, component)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(property.Value) {
+				return nil, fmt.Errorf("ical: line %d: unexpected END:%s", number+1, property.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("ical: line %d: %s outside of a component", number+1, property.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, property)
+		}
+	}
+	if root == nil {
+		return nil, errors.New("ical: no component found")
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("ical: missing END:%s", stack[len(stack)-1].Name)
+	}
+	resolveTimezones(root)
+	return root, nil
+}
+
+// The content lines of r, the lines starting with a space or a tab being
+// the continuation of the previous one
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(lines) > 0 && line != "" && (line[0] == ' ' || line[0] == '\t') {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, scanner.Err()
+}
+
+// Splits "NAME;PARAM=VALUE;PARAM="QUOTED:VALUE":value" at the separators
+// out of quotes
+func parseLine(line string) (Property, error) {
+	property := Property{}
+	quoted := false
+	start := 0
+	param := ""
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == ';' || c == ':':
+			field := line[start:i]
+			if property.Name == "" {
+				if field == "" {
+					return property, errors.New("missing property name")
+				}
+				property.Name = strings.ToUpper(field)
+			} else if err := property.addParam(param, field); err != nil {
+				return property, err
+			}
+			if c == ':' {
+				property.Value = line[i+1:]
+				return property, nil
+			}
+			start, param = i+1, ""
+		case c == '=' && param == "" && property.Name != "":
+			param = line[start:i]
+			start = i + 1
+		}
+	}
+	return property, fmt.Errorf("missing ':' in %q", line)
+}
+
+func (p *Property) addParam(name, value string) error {
+	if name == "" {
+		return fmt.Errorf("invalid parameter %q of %s", value, p.Name)
+	}
+	if p.Params == nil {
+		p.Params = map[string]string{}
+	}
+	p.Params[strings.ToUpper(name)] = strings.Replace(value, `"`, "", -1)
+	return nil
+}
+
+// All returns the properties named name, such as the EXDATEs of an event.
+func (c *Component) All(name string) []Property {
+	properties := []Property{}
+	for _, property := range c.Properties {
+		if property.Name == name {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// Unescape reads a TEXT value, the opposite of Text.
+func Unescape(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
+
+// Time reads a DATE or DATE-TIME value, telling whether it is a DATE. Dates
+// are midnights in UTC. Date-times are in UTC with a trailing Z, in their
+// TZID, an IANA or Windows time zone or one defined by a VTIMEZONE of the
+// file, or else in floating.
+func (p Property) Time(floating *time.Location) (time.Time, bool, error) {
+	times, date, err := p.Times(floating)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(times) != 1 {
+		return time.Time{}, false, fmt.Errorf("%s must have a single value", p.Name)
+	}
+	return times[0], date, nil
+}
+
+// Times reads a comma separated list of DATE or DATE-TIME values, such as
+// the ones of an EXDATE, like Time.
+func (p Property) Times(floating *time.Location) ([]time.Time, bool, error) {
+	location, err := p.location(floating)
+	if err != nil {
+		return nil, false, err
+	}
+	date := p.Params["VALUE"] == "DATE"
+	times := []time.Time{}
+	for _, value := range strings.Split(p.Value, ",") {
+		t, isDate, err := parseTime(strings.TrimSpace(value), location)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid %s: %v", p.Name, err)
+		}
+		date = date || isDate
+		times = append(times, t)
+	}
+	return times, date, nil
+}
+
+// Periods reads the comma separated "start/end" or "start/duration" values
+// of a FREEBUSY property.
+func (p Property) Periods() ([]Period, error) {
+	periods := []Period{}
+	for _, value := range strings.Split(p.Value, ",") {
+		parts := strings.Split(strings.TrimSpace(value), "/")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid %s period %q", p.Name, value)
+		}
+		start, _, err := parseTime(parts[0], time.UTC)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", p.Name, err)
+		}
+		period := Period{Start: start}
+		if strings.HasPrefix(parts[1], "P") || strings.HasPrefix(parts[1], "+P") {
+			duration, err := ParseDuration(parts[1])
+			if err != nil {
+				return nil, err
+			}
+			period.End = start.Add(duration)
+		} else if period.End, _, err = parseTime(parts[1], time.UTC); err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", p.Name, err)
+		}
+		periods = append(periods, period)
+	}
+	return periods, nil
+}
+
+func (p Property) location(floating *time.Location) (*time.Location, error) {
+	tzid, ok := p.Params["TZID"]
+	if !ok {
+		return floating, nil
+	}
+	if p.zone != nil {
+		return p.zone, nil
+	}
+	location, err := LoadLocation(tzid)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q of %s, use an IANA or Windows time zone", tzid, p.Name)
+	}
+	return location, nil
+}
+
+// Accepts "20261104", "20261104T090000Z" and "20261104T090000" in location
+func parseTime(value string, location *time.Location) (time.Time, bool, error) {
+	if len(value) == len(dateLayout) {
+		t, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return t, true, fmt.Errorf("invalid date %q, expected YYYYMMDD", value)
+		}
+		return t, true, nil
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcLayout, value)
+		if err != nil {
+			return t, false, fmt.Errorf("invalid date-time %q, expected YYYYMMDDTHHMMSSZ", value)
+		}
+		return t, false, nil
+	}
+	t, err := time.ParseInLocation(localLayout, value, location)
+	if err != nil {
+		return t, false, fmt.Errorf("invalid date-time %q, expected YYYYMMDDTHHMMSS", value)
+	}
+	return t, false, nil
+}
+
+// ParseDuration reads a DURATION value such as "PT1H30M", "-PT15M" or
+// "P1W", days and weeks being 24 hours long.
+func ParseDuration(value string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid duration %q, expected one such as PT1H30M", value)
+	rest := strings.TrimPrefix(value, "+")
+	sign := time.Duration(1)
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) < 3 {
+		return 0, invalid
+	}
+	rest = rest[1:]
+	units := map[byte]time.Duration{'W': 7 * 24 * time.Hour, 'D': 24 * time.Hour}
+	var duration time.Duration
+	number := ""
+	for i := 0; i < len(rest); i++ {
+		c := rest[i]
+		switch {
+		case c >= '0' && c <= '9':
+			number += string(c)
+		case c == 'T':
+			if number != "" || units['H'] != 0 {
+				return 0, invalid
+			}
+			units = map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}
+		default:
+			unit, ok := units[c]
+			n, err := strconv.Atoi(number)
+			if !ok || err != nil {
+				return 0, invalid
+			}
+			duration += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, invalid
+	}
+	return sign * duration, nil
+}
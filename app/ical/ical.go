@@ -0,0 +1,289 @@
+// Package ical reads and writes RFC 5545 iCalendar data: the calendar feeds
+// of the candidates and interviewers, with their slots and interviews as
+// VEVENTs, and the calendars they import.
+package ical
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Component is a BEGIN:NAME ... END:NAME block, such as a VCALENDAR holding
+// VEVENTs.
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// Property is a content line, such as "DTSTART;TZID=Europe/Berlin:20261104T090000".
+// Its Value is written as is, Text escapes the text values.
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+	// Time zone of a TZID only defined by a VTIMEZONE of the file
+	zone *time.Location
+}
+
+const (
+	localLayout    = "20060102T150405"
+	utcLayout      = "20060102T150405Z"
+	maxLineOctets  = 75
+	contentNewline = "\r\n"
+)
+
+// NewCalendar returns a VCALENDAR published by prodId.
+func NewCalendar(prodId string) *Component {
+	calendar := &Component{Name: "VCALENDAR"}
+	calendar.Add("VERSION", "2.0")
+	calendar.Add("PRODID", prodId)
+	calendar.Add("CALSCALE", "GREGORIAN")
+	return calendar
+}
+
+// Add appends a property whose value is already formatted.
+func (c *Component) Add(name, value string) {
+	c.Properties = append(c.Properties, Property{Name: name, Value: value})
+}
+
+// AddText appends a TEXT property, escaping its value.
+func (c *Component) AddText(name, value string) {
+	c.Add(name, Text(value))
+}
+
+// AddTime appends a DATE-TIME property in UTC.
+func (c *Component) AddTime(name string, t time.Time) {
+	c.Add(name, t.UTC().Format(utcLayout))
+}
+
+// AddLocalTime appends a DATE-TIME property with the wall clock of location
+// and its TZID, or in UTC when location is UTC. Every TZID needs a VTIMEZONE
+// in the calendar, see Timezone.
+func (c *Component) AddLocalTime(name string, t time.Time, location *time.Location) {
+	if location == time.UTC {
+		c.AddTime(name, t)
+		return
+	}
+	c.Properties = append(c.Properties, Property{
+		Name:   name,
+		Params: map[string]string{"TZID": location.String()},
+		Value:  t.In(location).Format(localLayout),
+	})
+}
+
+// AddFreeBusy appends a FREEBUSY property of type fbType, such as BUSY or
+// BUSY-UNAVAILABLE, with the periods in UTC. Nothing is added without
+// periods.
+func (c *Component) AddFreeBusy(fbType string, periods []Period) {
+	if len(periods) == 0 {
+		return
+	}
+	values := []string{}
+	for _, period := range periods {
+		values = append(values, period.Start.UTC().Format(utcLayout)+"/"+period.End.UTC().Format(utcLayout))
+	}
+	c.Properties = append(c.Properties, Property{
+		Name:   "FREEBUSY",
+		Params: map[string]string{"FBTYPE": fbType},
+		Value:  strings.Join(values, ","),
+	})
+}
+
+// Property returns the first property named name, nil when there is none.
+func (c *Component) Property(name string) *Property {
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Encode writes the component with CRLF line endings, folding the lines
+// longer than 75 octets.
+func (c *Component) Encode(w io.Writer) error {
+	lines := []string{}
+	c.lines(&lines)
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+contentNewline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Component) lines(lines *[]string) {
+	*lines = append(*lines, "BEGIN:"+c.Name)
+	for _, property := range c.Properties {
+		*lines = append(*lines, property.String())
+	}
+	for _, component := range c.Components {
+		component.lines(lines)
+	}
+	*lines = append(*lines, "END:"+c.Name)
+}
+
+// String returns the unfolded content line of the property, its parameters
+// sorted by name.
+func (p Property) String() string {
+	names := []string{}
+	for name := range p.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	line := p.Name
+	for _, name := range names {
+		line += ";" + name + "=" + paramValue(p.Params[name])
+	}
+	return line + ":" + p.Value
+}
+
+// Text escapes a TEXT value: backslashes, semicolons, commas and newlines.
+func Text(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// Parameter values with separators are quoted
+func paramValue(value string) string {
+	if strings.ContainsAny(value, ":;,") {
+		return `"` + strings.Replace(value, `"`, "", -1) + `"`
+	}
+	return value
+}
+
+// Splits the line in lines of at most 75 octets, the next ones starting
+// with a space, without cutting a UTF-8 character
+func fold(line string) string {
+	if len(line) <= maxLineOctets {
+		return line
+	}
+	var folded strings.Builder
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + contentNewline + " ")
+		line = line[cut:]
+		// The leading space counts in the next lines
+		limit = maxLineOctets - 1
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
+
+// Timezone returns the VTIMEZONE of location from from to to, found from
+// Go's time zone database: the observance in effect at from, one per offset
+// change until to, and the next two changes, which repeat every year with an
+// RRULE when the zone follows such a rule.
+func Timezone(location *time.Location, from, to time.Time) *Component {
+	timezone := &Component{Name: "VTIMEZONE"}
+	timezone.Add("TZID", location.String())
+
+	// The observance in effect at from, since it actually started
+	start, end := from.In(location).ZoneBounds()
+	_, previous := from.In(location).Zone()
+	if !start.IsZero() {
+		_, previous = start.Add(-time.Second).In(location).Zone()
+	}
+	timezone.Components = append(timezone.Components, observance(from.In(location), start, previous))
+
+	changes := []time.Time{}
+	for after := 0; !end.IsZero() && after < 2; _, end = end.In(location).ZoneBounds() {
+		// Go bounds the zones at the end of its data too, changing nothing
+		name, offset := end.In(location).Zone()
+		beforeName, beforeOffset := end.Add(-time.Second).In(location).Zone()
+		if name == beforeName && offset == beforeOffset {
+			continue
+		}
+		if end.After(to) {
+			after++
+		}
+		changes = append(changes, end)
+	}
+	rules := []string{}
+	for _, change := range changes {
+		_, previous := change.Add(-time.Second).In(location).Zone()
+		timezone.Components = append(timezone.Components, observance(change.In(location), change, previous))
+		rules = append(rules, yearlyRule(location, change, previous))
+	}
+	// The last two changes go on, every year, past the end of the database
+	if last := len(changes) - 1; last > 0 && rules[last-1] != "" && rules[last] != "" {
+		for i := last - 1; i <= last; i++ {
+			timezone.Components[i+1].Add("RRULE", rules[i])
+		}
+	}
+	return timezone
+}
+
+// The observance of the offset of at, which took over from previous at
+// start. Zones that never changed start at the usual 1970 epoch.
+func observance(at time.Time, start time.Time, previous int) *Component {
+	kind := "STANDARD"
+	if at.IsDST() {
+		kind = "DAYLIGHT"
+	}
+	name, offset := at.Zone()
+	// DTSTART is the local time of the change, before it
+	local := "19700101T000000"
+	if !start.IsZero() {
+		local = start.In(time.FixedZone("", previous)).Format(localLayout)
+	}
+	component := &Component{Name: kind}
+	component.Add("DTSTART", local)
+	component.Add("TZOFFSETFROM", formatOffset(previous))
+	component.Add("TZOFFSETTO", formatOffset(offset))
+	component.AddText("TZNAME", name)
+	return component
+}
+
+// The "FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU" rule of the offset change at
+// change, when location changes the same way on the same weekday of the
+// month and at the same wall clock time the next year, or else ""
+func yearlyRule(location *time.Location, change time.Time, previous int) string {
+	before := time.FixedZone("", previous)
+	local := change.In(before)
+	week := (local.Day()-1)/7 + 1
+	if local.AddDate(0, 0, 7).Month() != local.Month() {
+		week = -1
+	}
+	day := nthWeekday(local.Year()+1, local.Month(), week, local.Weekday())
+	next := time.Date(day.Year(), local.Month(), day.Day(), local.Hour(), local.Minute(), local.Second(), 0, before)
+	start, _ := next.In(location).ZoneBounds()
+	_, offset := change.In(location).Zone()
+	_, nextOffset := next.In(location).Zone()
+	_, nextPrevious := next.Add(-time.Second).In(location).Zone()
+	if !start.Equal(next) || nextOffset != offset || nextPrevious != previous {
+		return ""
+	}
+	return fmt.Sprintf("FREQ=YEARLY;BYMONTH=%d;BYDAY=%d%s", local.Month(), week, strings.ToUpper(local.Weekday().String()[:2]))
+}
+
+// The week-th weekday of the month, the last one for -1
+func nthWeekday(year int, month time.Month, week int, weekday time.Weekday) time.Time {
+	if week < 0 {
+		last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		return last.AddDate(0, 0, -((int(last.Weekday()) - int(weekday) + 7) % 7))
+	}
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 0, (int(weekday)-int(first.Weekday())+7)%7+7*(week-1))
+}
+
+// Offsets in seconds east of UTC, as +0100 or -0330
+func formatOffset(offset int) string {
+	sign := "+"
+	if offset < 0 {
+		sign, offset = "-", -offset
+	}
+	formatted := fmt.Sprintf("%s%02d%02d", sign, offset/3600, offset/60%60)
+	if offset%60 != 0 {
+		formatted += fmt.Sprintf("%02d", offset%60)
+	}
+	return formatted
+}
@@ -0,0 +1,115 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimezone(t *testing.T) {
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		location string
+		from, to time.Time
+		want     string
+	}{
+		{
+			name:     "in winter",
+			location: "Europe/Berlin",
+			from:     day(2026, time.November, 4),
+			to:       day(2026, time.November, 4),
+			want: `BEGIN:VTIMEZONE
+TZID:Europe/Berlin
+BEGIN:STANDARD
+DTSTART:20261025T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+TZNAME:CET
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:20270328T020000
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+TZNAME:CEST
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20271031T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+TZNAME:CET
+RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU
+END:STANDARD
+END:VTIMEZONE`,
+		},
+		{
+			name:     "over several changes",
+			location: "America/New_York",
+			from:     day(2026, time.July, 4),
+			to:       day(2026, time.December, 1),
+			want: `BEGIN:VTIMEZONE
+TZID:America/New_York
+BEGIN:DAYLIGHT
+DTSTART:20260308T020000
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20261101T020000
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+TZNAME:EST
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:20270314T020000
+TZOFFSETFROM:-0500
+TZOFFSETTO:-0400
+TZNAME:EDT
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20271107T020000
+TZOFFSETFROM:-0400
+TZOFFSETTO:-0500
+TZNAME:EST
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU
+END:STANDARD
+END:VTIMEZONE`,
+		},
+		{
+			name:     "without daylight saving time any more",
+			location: "America/Sao_Paulo",
+			from:     day(2026, time.November, 4),
+			to:       day(2026, time.November, 4),
+			want: `BEGIN:VTIMEZONE
+TZID:America/Sao_Paulo
+BEGIN:STANDARD
+DTSTART:20190217T000000
+TZOFFSETFROM:-0200
+TZOFFSETTO:-0300
+TZNAME:-03
+END:STANDARD
+END:VTIMEZONE`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			location, err := time.LoadLocation(test.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded strings.Builder
+			if err = Timezone(location, test.from, test.to).Encode(&encoded); err != nil {
+				t.Fatal(err)
+			}
+			got := strings.TrimSpace(strings.ReplaceAll(encoded.String(), "\r\n", "\n"))
+			if got != test.want {
+				t.Errorf("got\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package ical
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LoadLocation returns the time zone of a TZID, an IANA time zone such as
+// Europe/Berlin or a Windows one such as "W. Europe Standard Time", as
+// Outlook and Exchange name them.
+func LoadLocation(tzid string) (*time.Location, error) {
+	name := strings.TrimPrefix(tzid, "/")
+	if location, err := time.LoadLocation(name); err == nil {
+		return location, nil
+	}
+	if iana, ok := windowsZones[name]; ok {
+		return time.LoadLocation(iana)
+	}
+	return nil, fmt.Errorf("unknown time zone %q", tzid)
+}
+
+// Gives the properties whose TZID is neither an IANA nor a Windows time zone
+// the time zone of the VTIMEZONE of the calendar with their TZID
+func resolveTimezones(calendar *Component) {
+	zones := map[string]*time.Location{}
+	for _, component := range calendar.Components {
+		tzid := component.Property("TZID")
+		if component.Name != "VTIMEZONE" || tzid == nil {
+			continue
+		}
+		if _, err := LoadLocation(tzid.Value); err == nil {
+			continue
+		}
+		if location, err := timezoneLocation(tzid.Value, component); err == nil {
+			zones[tzid.Value] = location
+		}
+	}
+	if len(zones) > 0 {
+		setTimezones(calendar, zones)
+	}
+}
+
+func setTimezones(component *Component, zones map[string]*time.Location) {
+	for i := range component.Properties {
+		if tzid, ok := component.Properties[i].Params["TZID"]; ok {
+			component.Properties[i].zone = zones[tzid]
+		}
+	}
+	for _, child := range component.Components {
+		setTimezones(child, zones)
+	}
+}
+
+// A VTIMEZONE observance, from its DTSTART on
+type zoneObservance struct {
+	daylight bool
+	start    time.Time
+	name     string
+	offset   int
+	rule     string
+}
+
+// The time zone following the latest STANDARD and DAYLIGHT observances of
+// the VTIMEZONE, every year when they have a yearly RRULE on a weekday of a
+// month, as in "FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU", and for good otherwise
+func timezoneLocation(tzid string, timezone *Component) (*time.Location, error) {
+	var standard, daylight *zoneObservance
+	for _, component := range timezone.Components {
+		if component.Name != "STANDARD" && component.Name != "DAYLIGHT" {
+			continue
+		}
+		current, err := readObservance(component)
+		if err != nil {
+			return nil, err
+		}
+		latest := &standard
+		if current.daylight {
+			latest = &daylight
+		}
+		if *latest == nil || current.start.After((*latest).start) {
+			*latest = &current
+		}
+	}
+	if standard == nil {
+		standard, daylight = daylight, nil
+	}
+	if standard == nil {
+		return nil, fmt.Errorf("VTIMEZONE %s has no observance", tzid)
+	}
+
+	// A POSIX TZ string, as in "<CET>-1<CEST>-2,M3.5.0/2,M10.5.0/3"
+	extend := posixName(standard.name) + posixOffset(standard.offset)
+	if daylight != nil {
+		toDaylight, okDaylight := posixRule(*daylight)
+		toStandard, okStandard := posixRule(*standard)
+		if okDaylight && okStandard {
+			extend += posixName(daylight.name) + posixOffset(daylight.offset) + "," + toDaylight + "," + toStandard
+		}
+	}
+	return time.LoadLocationFromTZData(tzid, tzData(standard.offset, extend))
+}
+
+func readObservance(component *Component) (zoneObservance, error) {
+	current := zoneObservance{daylight: component.Name == "DAYLIGHT"}
+	start := component.Property("DTSTART")
+	offset := component.Property("TZOFFSETTO")
+	if start == nil || offset == nil {
+		return current, fmt.Errorf("%s needs a DTSTART and a TZOFFSETTO", component.Name)
+	}
+	var err error
+	if current.start, err = time.Parse(localLayout, start.Value); err != nil {
+		return current, fmt.Errorf("invalid %s DTSTART %q", component.Name, start.Value)
+	}
+	if current.offset, err = parseOffset(offset.Value); err != nil {
+		return current, err
+	}
+	if name := component.Property("TZNAME"); name != nil {
+		current.name = Unescape(name.Value)
+	}
+	if rule := component.Property("RRULE"); rule != nil {
+		current.rule = strings.ToUpper(rule.Value)
+	}
+	return current, nil
+}
+
+// Reads a UTC offset such as "+0100", "-0330" or "+053000" into seconds
+func parseOffset(value string) (int, error) {
+	invalid := fmt.Errorf("invalid UTC offset %q", value)
+	if len(value) != 5 && len(value) != 7 || value[0] != '+' && value[0] != '-' {
+		return 0, invalid
+	}
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i >= len(value) {
+			break
+		}
+		n, err := strconv.Atoi(value[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, invalid
+		}
+		seconds += n * unit
+	}
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// The "Mm.w.d/time" POSIX rule of an observance happening every year on
+// the nth or last weekday of a month, at the wall clock time of its DTSTART
+func posixRule(o zoneObservance) (string, bool) {
+	var month, week, weekday int
+	for _, part := range strings.Split(o.rule, ";") {
+		name, value := part, ""
+		if i := strings.IndexByte(part, '='); i >= 0 {
+			name, value = part[:i], part[i+1:]
+		}
+		var err error
+		switch name {
+		case "FREQ":
+			if value != "YEARLY" {
+				return "", false
+			}
+		case "BYMONTH":
+			if month, err = strconv.Atoi(value); err != nil || month < 1 || month > 12 {
+				return "", false
+			}
+		case "BYDAY":
+			if len(value) < 3 {
+				return "", false
+			}
+			if weekday = strings.Index("SUMOTUWETHFRSA", value[len(value)-2:]); weekday < 0 || weekday%2 != 0 {
+				return "", false
+			}
+			weekday /= 2
+			if week, err = strconv.Atoi(value[:len(value)-2]); err != nil || week == 0 || week < -1 || week > 5 {
+				return "", false
+			}
+			if week == -1 {
+				week = 5
+			}
+		case "UNTIL":
+			// The observance ended, another one follows it
+			return "", false
+		}
+	}
+	if month == 0 || week == 0 {
+		return "", false
+	}
+	clock := o.start.Hour()*3600 + o.start.Minute()*60 + o.start.Second()
+	return fmt.Sprintf("M%d.%d.%d/%d:%02d:%02d", month, week, weekday, clock/3600, clock/60%60, clock%60), true
+}
+
+// The name of a POSIX TZ string, quoted so that it may hold any character
+// but '>'
+func posixName(name string) string {
+	name = strings.Replace(name, ">", "", -1)
+	if len(name) < 3 {
+		name = "TZ" + name
+	}
+	return "<" + name + ">"
+}
+
+// POSIX offsets are the time to add to get to UTC, west of it positive
+func posixOffset(offset int) string {
+	sign := "-"
+	if offset <= 0 {
+		sign, offset = "", -offset
+	}
+	return fmt.Sprintf("%s%d:%02d:%02d", sign, offset/3600, offset/60%60, offset%60)
+}
+
+// A version 2 TZif file, from RFC 8536, without transitions: the footer TZ
+// string gives the time zone of every time
+func tzData(offset int, extend string) []byte {
+	const abbreviation = "TZ\x00"
+	block := func(data []byte) []byte {
+		data = append(data, "TZif2"...)
+		data = append(data, make([]byte, 15)...)
+		// isutcnt, isstdcnt, leapcnt, timecnt, typecnt and charcnt
+		for _, count := range []uint32{0, 0, 0, 0, 1, uint32(len(abbreviation))} {
+			data = binary.BigEndian.AppendUint32(data, count)
+		}
+		data = binary.BigEndian.AppendUint32(data, uint32(int32(offset)))
+		data = append(data, 0, 0)
+		return append(data, abbreviation...)
+	}
+	data := block(nil)
+	data = block(data)
+	return append(data, "\n"+extend+"\n"...)
+}
@@ -0,0 +1,100 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// The DTSTART of the single VEVENT of a VCALENDAR holding timezones
+func eventStart(t *testing.T, timezones, start string) (time.Time, error) {
+	t.Helper()
+	body := "BEGIN:VCALENDAR\nVERSION:2.0\n" + strings.TrimSpace(timezones) + "\nBEGIN:VEVENT\nUID:event\n" + start + "\nEND:VEVENT\nEND:VCALENDAR\n"
+	calendar, err := Decode(strings.NewReader(strings.ReplaceAll(body, "\n", "\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := calendar.Components[len(calendar.Components)-1]
+	at, _, err := event.Property("DTSTART").Time(time.UTC)
+	return at, err
+}
+
+// As Outlook writes them, with a TZID of its own
+const customized = `
+BEGIN:VTIMEZONE
+TZID:Customized Time Zone
+BEGIN:STANDARD
+DTSTART:16010101T030000
+TZOFFSETFROM:+0200
+TZOFFSETTO:+0100
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10
+END:STANDARD
+BEGIN:DAYLIGHT
+DTSTART:16010101T020000
+TZOFFSETFROM:+0100
+TZOFFSETTO:+0200
+RRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3
+END:DAYLIGHT
+END:VTIMEZONE`
+
+// A zone that gave up daylight saving time, its last DAYLIGHT ending
+const abolished = `
+BEGIN:VTIMEZONE
+TZID:Former Zone
+BEGIN:DAYLIGHT
+DTSTART:20100314T020000
+TZOFFSETFROM:-0300
+TZOFFSETTO:-0200
+RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=2SU;UNTIL=20180311T050000Z
+END:DAYLIGHT
+BEGIN:STANDARD
+DTSTART:20101107T020000
+TZOFFSETFROM:-0200
+TZOFFSETTO:-0300
+RRULE:FREQ=YEARLY;BYMONTH=11;BYDAY=1SU
+END:STANDARD
+END:VTIMEZONE`
+
+func TestTimezones(t *testing.T) {
+	tests := []struct {
+		name      string
+		timezones string
+		start     string
+		want      string
+	}{
+		{"IANA", "", "DTSTART;TZID=Europe/Berlin:20260704T090000", "2026-07-04T07:00:00Z"},
+		{"IANA with a leading slash", "", "DTSTART;TZID=/Europe/Berlin:20261104T090000", "2026-11-04T08:00:00Z"},
+		{"Windows in summer", "", `DTSTART;TZID="W. Europe Standard Time":20260704T090000`, "2026-07-04T07:00:00Z"},
+		{"Windows in winter", "", `DTSTART;TZID="Pacific Standard Time":20261204T090000`, "2026-12-04T17:00:00Z"},
+		{"VTIMEZONE in summer", customized, `DTSTART;TZID="Customized Time Zone":20260704T090000`, "2026-07-04T07:00:00Z"},
+		{"VTIMEZONE in winter", customized, `DTSTART;TZID="Customized Time Zone":20261104T090000`, "2026-11-04T08:00:00Z"},
+		{"VTIMEZONE on the last Sunday of March", customized, `DTSTART;TZID="Customized Time Zone":20270328T090000`, "2027-03-28T07:00:00Z"},
+		{"VTIMEZONE without daylight saving time any more", abolished, `DTSTART;TZID="Former Zone":20260704T090000`, "2026-07-04T12:00:00Z"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := eventStart(t, test.timezones, test.start)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.UTC().Format(time.RFC3339) != test.want {
+				t.Errorf("got %s, want %s", got.UTC().Format(time.RFC3339), test.want)
+			}
+		})
+	}
+}
+
+func TestUnknownTimezone(t *testing.T) {
+	_, err := eventStart(t, "", "DTSTART;TZID=Mars/Olympus_Mons:20261104T090000")
+	if err == nil || !strings.Contains(err.Error(), `unknown time zone "Mars/Olympus_Mons"`) {
+		t.Errorf("got %v, want an unknown time zone error", err)
+	}
+}
+
+func TestWindowsZones(t *testing.T) {
+	for windows, iana := range windowsZones {
+		if _, err := time.LoadLocation(iana); err != nil {
+			t.Errorf("%s: %v", windows, err)
+		}
+	}
+}
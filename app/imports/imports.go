@@ -0,0 +1,412 @@
+// Package imports turns the components of iCalendar files into the slots and
+// exceptions of a person: free VEVENTs become slots, busy VEVENTs and
+// VFREEBUSY periods exceptions. Every entry keeps the UID it came from, with
+// the occurrence of a recurring event after it, so that a file can be
+// imported again.
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/rrule"
+)
+
+// Years after today over which a rule ended by its COUNT is searched for an
+// occurrence left
+const maxRuleYears = 100
+
+// MaxText is the longest UID and exception reason, the size of their columns.
+const MaxText = 255
+
+// Import reads the entries of iCalendar files for a person of Location,
+// queueing the new slots and exceptions in Pending and reporting on every
+// entry. The entries whose UID is in Imported are skipped.
+type Import struct {
+	Location *time.Location
+	Now      time.Time
+	// Days after Now over which recurring busy events are expanded
+	Horizon int
+	// UIDs of the slots and exceptions already imported
+	Imported map[string]bool
+	// The entries to create, also to be reported as Created once they are
+	Pending []model.ImportEntry
+	Report  model.ImportReport
+	// Occurrences of recurring events replaced by a RECURRENCE-ID, by UID
+	replaced map[string][]time.Time
+}
+
+// New returns an Import with nothing imported yet.
+func New(location *time.Location, now time.Time, horizon int) *Import {
+	return &Import{
+		Location: location,
+		Now:      now,
+		Horizon:  horizon,
+		Imported: map[string]bool{},
+		Pending:  []model.ImportEntry{},
+		Report:   model.ImportReport{Created: []model.ImportEntry{}, Skipped: []model.ImportEntry{}, Invalid: []model.ImportEntry{}},
+		replaced: map[string][]time.Time{},
+	}
+}
+
+// Read queues the entries of the components of the calendar.
+func (i *Import) Read(calendar *ical.Component) {
+	for _, component := range calendar.Components {
+		recurrence := component.Property("RECURRENCE-ID")
+		if component.Name != "VEVENT" || recurrence == nil {
+			continue
+		}
+		if id, _, err := recurrence.Time(i.Location); err == nil {
+			uid := Text(component, "UID")
+			i.replaced[uid] = append(i.replaced[uid], id)
+		}
+	}
+
+	for _, component := range calendar.Components {
+		switch component.Name {
+		case "VEVENT":
+			i.event(component)
+		case "VFREEBUSY":
+			i.freeBusy(component)
+		case "VTIMEZONE":
+			// ical.Decode already resolved the TZIDs it defines
+		default:
+			i.skip(model.ImportEntry{Uid: Text(component, "UID"), Summary: Text(component, "SUMMARY")}, component.Name+" components aren't imported")
+		}
+	}
+}
+
+func (i *Import) event(event *ical.Component) {
+	entry := model.ImportEntry{Uid: Text(event, "UID"), Summary: Text(event, "SUMMARY")}
+	if err := CheckUid(entry.Uid); err != nil {
+		i.invalid(entry, err)
+		return
+	}
+	if strings.EqualFold(Text(event, "STATUS"), "CANCELLED") {
+		i.skip(entry, "the event is cancelled")
+		return
+	}
+	start, end, date, err := EventTimes(event, i.Location)
+	if err != nil {
+		i.invalid(entry, err)
+		return
+	}
+
+	// A RECURRENCE-ID event is a single occurrence of a recurring one
+	var rule *rrule.Rule
+	excluded := []time.Time{}
+	if recurrence := event.Property("RECURRENCE-ID"); recurrence != nil {
+		id, idDate, err := recurrence.Time(i.Location)
+		if err != nil {
+			i.invalid(entry, err)
+			return
+		}
+		entry.Uid += "/" + OccurrenceKey(id, idDate)
+	} else {
+		if property := event.Property("RRULE"); property != nil {
+			if rule, err = rrule.ParseIn(property.Value, start.Location()); err != nil {
+				i.invalid(entry, err)
+				return
+			}
+		}
+		for _, property := range event.All("EXDATE") {
+			exdates, _, err := property.Times(i.Location)
+			if err != nil {
+				i.invalid(entry, err)
+				return
+			}
+			excluded = append(excluded, exdates...)
+		}
+	}
+
+	if free(event) {
+		i.freeEvent(entry, start, end, date, rule, excluded)
+		return
+	}
+	if rule == nil {
+		i.busy(entry, start, end, date)
+		return
+	}
+	// Recurring busy events are expanded over the Horizon, less their
+	// excluded and replaced occurrences
+	excluded = append(excluded, i.replaced[entry.Uid]...)
+	from := i.Now.In(start.Location()).AddDate(0, 0, -1)
+	occurrences := 0
+	for _, day := range rule.Between(start, from, from.AddDate(0, 0, i.Horizon)) {
+		occurrence := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+		if isExcluded(occurrence, date, excluded) {
+			continue
+		}
+		occurrenceEnd := occurrence.Add(end.Sub(start))
+		if date {
+			occurrenceEnd = occurrence.AddDate(0, 0, int(end.Sub(start)/(24*time.Hour)))
+		}
+		if !occurrenceEnd.After(i.Now) {
+			continue
+		}
+		occurrences++
+		i.busy(model.ImportEntry{Uid: entry.Uid + "/" + OccurrenceKey(occurrence, date), Summary: entry.Summary}, occurrence, occurrenceEnd, date)
+	}
+	if occurrences == 0 {
+		i.skip(entry, fmt.Sprintf("the event has no occurrence in the next %d days", i.Horizon))
+	}
+}
+
+// A free event becomes a slot on the day it happens, recurring with its
+// RRULE. Its excluded and replaced occurrences become exceptions, so that
+// the slot isn't offered on them, the replacing events having their own
+// slots or exceptions.
+func (i *Import) freeEvent(entry model.ImportEntry, start, end time.Time, date bool, rule *rrule.Rule, excluded []time.Time) {
+	slot, err := EventSlot(start, end, date, i.Location)
+	if err != nil {
+		i.invalid(entry, err)
+		return
+	}
+	if rule != nil {
+		// The RRULE happens on the days of the event's own time zone
+		if !date && model.DateOf(start) != *slot.Date {
+			i.invalid(entry, errors.New("a recurring free event must be on the same day in the person's time zone"))
+			return
+		}
+		slot.RRule = rule.String()
+	}
+	if err = slot.Validate(); err != nil {
+		i.invalid(entry, err)
+		return
+	}
+	ended := end.Before(i.Now)
+	if rule != nil {
+		// Ended by its UNTIL or its COUNT when no occurrence is left from
+		// today, looking as far as the longest rules go
+		today := i.Now.In(i.Location)
+		ended = (!rule.Until.IsZero() || rule.Count > 0) && len(rule.Between(start, today, today.AddDate(maxRuleYears, 0, 0))) == 0
+	}
+	if ended {
+		i.skip(entry, "the event is in the past")
+		return
+	}
+	entry.Slot = &slot
+	i.add(entry)
+
+	for _, exdate := range excluded {
+		i.exclude(entry, slot, exdate, date, "")
+	}
+	if rule != nil {
+		// Named apart from the events replacing them, which have their key
+		for _, id := range i.replaced[entry.Uid] {
+			i.exclude(entry, slot, id, date, "/replaced")
+		}
+	}
+}
+
+// An occurrence of a free recurring event becomes an exception for the
+// times of its slot on that day, unless it is over
+func (i *Import) exclude(entry model.ImportEntry, slot model.Slot, occurrence time.Time, date bool, suffix string) {
+	day := model.DateOf(occurrence.In(i.Location))
+	if date {
+		day = model.DateOf(occurrence)
+	}
+	exception := model.Exception{From: day, InitialTime: slot.InitialTime, To: day, FinalTime: slot.FinalTime, Reason: Truncate(entry.Summary)}
+	if exception.End(i.Location).Before(i.Now) {
+		return
+	}
+	excludedEntry := model.ImportEntry{Uid: entry.Uid + "/" + OccurrenceKey(occurrence, date) + suffix, Summary: entry.Summary}
+	excludedEntry.Exception = &exception
+	i.add(excludedEntry)
+}
+
+// A busy event or period becomes an exception
+func (i *Import) busy(entry model.ImportEntry, start, end time.Time, date bool) {
+	exception := model.Exception{Reason: Truncate(entry.Summary)}
+	if date {
+		exception.From, exception.To = model.DateOf(start), model.DateOf(end).AddDays(-1)
+		exception.FinalTime = model.EndOfDay
+	} else {
+		localStart, localEnd := start.In(i.Location), end.In(i.Location)
+		exception.From, exception.InitialTime = model.DateOf(localStart), timeOfDay(localStart)
+		exception.To, exception.FinalTime = model.DateOf(localEnd), timeOfDay(localEnd)
+		if exception.FinalTime == 0 && exception.From.Before(exception.To) {
+			exception.To, exception.FinalTime = exception.To.AddDays(-1), model.EndOfDay
+		}
+	}
+	if err := exception.Validate(); err != nil {
+		i.invalid(entry, err)
+		return
+	}
+	if !exception.End(i.Location).After(i.Now) {
+		i.skip(entry, "the event is in the past")
+		return
+	}
+	entry.Exception = &exception
+	i.add(entry)
+}
+
+// The busy periods of a VFREEBUSY become exceptions, its free ones are
+// skipped
+func (i *Import) freeBusy(freeBusy *ical.Component) {
+	entry := model.ImportEntry{Uid: Text(freeBusy, "UID"), Summary: Text(freeBusy, "SUMMARY")}
+	if err := CheckUid(entry.Uid); err != nil {
+		i.invalid(entry, err)
+		return
+	}
+	reason := entry.Summary
+	if reason == "" {
+		reason = "Busy"
+	}
+	for _, property := range freeBusy.All("FREEBUSY") {
+		if strings.EqualFold(property.Params["FBTYPE"], "FREE") {
+			i.skip(entry, "free periods aren't imported")
+			continue
+		}
+		periods, err := property.Periods()
+		if err != nil {
+			i.invalid(entry, err)
+			continue
+		}
+		for _, period := range periods {
+			i.busy(model.ImportEntry{Uid: entry.Uid + "/" + OccurrenceKey(period.Start, false), Summary: reason}, period.Start, period.End, false)
+		}
+	}
+}
+
+// Queues the slot or exception of the entry unless it was already imported
+func (i *Import) add(entry model.ImportEntry) {
+	if i.Imported[entry.Uid] {
+		i.skip(model.ImportEntry{Uid: entry.Uid, Summary: entry.Summary}, "already imported")
+		return
+	}
+	i.Imported[entry.Uid] = true
+	if entry.Slot != nil {
+		entry.Slot.Uid = entry.Uid
+	} else {
+		entry.Exception.Uid = entry.Uid
+	}
+	i.Pending = append(i.Pending, entry)
+}
+
+func (i *Import) skip(entry model.ImportEntry, reason string) {
+	entry.Reason = reason
+	i.Report.Skipped = append(i.Report.Skipped, entry)
+}
+
+func (i *Import) invalid(entry model.ImportEntry, err error) {
+	entry.Reason = err.Error()
+	i.Report.Invalid = append(i.Report.Invalid, entry)
+}
+
+// EventTimes returns the start and end of an event, from its DTEND or
+// DURATION, and whether they are dates. Without both a date lasts a day and
+// a date-time no time.
+func EventTimes(event *ical.Component, location *time.Location) (time.Time, time.Time, bool, error) {
+	property := event.Property("DTSTART")
+	if property == nil {
+		return time.Time{}, time.Time{}, false, errors.New("DTSTART is required")
+	}
+	start, date, err := property.Time(location)
+	if err != nil {
+		return start, start, date, err
+	}
+	end := start
+	if date {
+		end = start.AddDate(0, 0, 1)
+	}
+	if property = event.Property("DTEND"); property != nil {
+		end, _, err = property.Time(location)
+	} else if property = event.Property("DURATION"); property != nil {
+		var duration time.Duration
+		if duration, err = ical.ParseDuration(property.Value); err == nil {
+			end = start.Add(duration)
+		}
+	}
+	return start, end, date, err
+}
+
+// EventSlot returns the slot of an event in location, which must start and
+// end on the same day there.
+func EventSlot(start, end time.Time, date bool, location *time.Location) (model.Slot, error) {
+	notSameDay := errors.New("a free event must start and end on the same day")
+	if date {
+		day := model.DateOf(start)
+		if model.DateOf(end) != day.AddDays(1) {
+			return model.Slot{}, notSameDay
+		}
+		return model.Slot{Date: &day, InitialTime: 0, FinalTime: model.EndOfDay}, nil
+	}
+	localStart, localEnd := start.In(location), end.In(location)
+	day := model.DateOf(localStart)
+	final := timeOfDay(localEnd)
+	if endDay := model.DateOf(localEnd); endDay != day {
+		if endDay != day.AddDays(1) || final != 0 {
+			return model.Slot{}, notSameDay
+		}
+		final = model.EndOfDay
+	}
+	return model.Slot{Date: &day, InitialTime: timeOfDay(localStart), FinalTime: final}, nil
+}
+
+// Free events are the transparent ones, and the ones Outlook shows as free
+func free(event *ical.Component) bool {
+	return strings.EqualFold(Text(event, "TRANSP"), "TRANSPARENT") ||
+		strings.EqualFold(Text(event, "X-MICROSOFT-CDO-BUSYSTATUS"), "FREE")
+}
+
+func isExcluded(occurrence time.Time, date bool, excluded []time.Time) bool {
+	for _, exdate := range excluded {
+		if date && model.DateOf(exdate) == model.DateOf(occurrence) || !date && exdate.Equal(occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+// OccurrenceKey identifies an occurrence of a recurring entry next to its
+// UID.
+func OccurrenceKey(t time.Time, date bool) string {
+	if date {
+		return t.Format("20060102")
+	}
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// CheckUid checks the UID is there and leaves room for the occurrence keys
+// in MaxText.
+func CheckUid(uid string) error {
+	if uid == "" {
+		return errors.New("UID is required")
+	}
+	if len(uid) > MaxText-len("/20060102T150405Z") {
+		return fmt.Errorf("UID can't be longer than %d characters", MaxText-len("/20060102T150405Z"))
+	}
+	return nil
+}
+
+// Text returns the unescaped TEXT value of the property, empty when there
+// is none.
+func Text(component *ical.Component, name string) string {
+	if property := component.Property(name); property != nil {
+		return ical.Unescape(property.Value)
+	}
+	return ""
+}
+
+// The wall clock time of t
+func timeOfDay(t time.Time) model.TimeOfDay {
+	return model.TimeOfDay(t.Hour()*3600 + t.Minute()*60 + t.Second())
+}
+
+// Truncate cuts value to MaxText bytes, without cutting a UTF-8 character.
+func Truncate(value string) string {
+	if len(value) <= MaxText {
+		return value
+	}
+	cut := MaxText
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
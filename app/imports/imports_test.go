@@ -0,0 +1,287 @@
+package imports
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/model"
+)
+
+// Monday November 2nd 2026, 08:00 UTC
+var now = time.Date(2026, time.November, 2, 8, 0, 0, 0, time.UTC)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+// The components of a VCALENDAR read for a person in Berlin, UIDs in
+// imported being already imported
+func read(t *testing.T, components string, imported ...string) *Import {
+	t.Helper()
+	body := "BEGIN:VCALENDAR\nVERSION:2.0\nPRODID:-//Test//EN\n" + strings.TrimSpace(components) + "\nEND:VCALENDAR\n"
+	calendar, err := ical.Decode(strings.NewReader(strings.ReplaceAll(body, "\n", "\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := New(mustLoad(t, "Europe/Berlin"), now, 14)
+	for _, uid := range imported {
+		i.Imported[uid] = true
+	}
+	i.Read(calendar)
+	return i
+}
+
+func describe(entries []model.ImportEntry) []string {
+	described := []string{}
+	for _, entry := range entries {
+		switch {
+		case entry.Slot != nil:
+			slot := entry.Slot
+			described = append(described, fmt.Sprintf("slot %s %s %s-%s %s", slot.Uid, slot.Date, slot.InitialTime, slot.FinalTime, slot.RRule))
+		case entry.Exception != nil:
+			exception := entry.Exception
+			described = append(described, fmt.Sprintf("exception %s %s %s-%s %s %s", exception.Uid, exception.From, exception.InitialTime, exception.To, exception.FinalTime, exception.Reason))
+		default:
+			described = append(described, entry.Uid+": "+entry.Reason)
+		}
+	}
+	return described
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name       string
+		components string
+		imported   []string
+		pending    []string
+		skipped    []string
+		invalid    []string
+	}{
+		{
+			name: "free event",
+			components: `
+BEGIN:VEVENT
+UID:free
+DTSTART:20261104T090000Z
+DTEND:20261104T120000Z
+TRANSP:TRANSPARENT
+END:VEVENT`,
+			pending: []string{"slot free 2026-11-04 10:00:00-13:00:00 "},
+		},
+		{
+			name: "recurring free event in its own time zone",
+			components: `
+BEGIN:VEVENT
+UID:office-hours
+DTSTART;TZID=America/New_York:20261104T090000
+DTEND;TZID=America/New_York:20261104T100000
+RRULE:FREQ=WEEKLY;BYDAY=WE
+EXDATE;TZID=America/New_York:20261111T090000
+X-MICROSOFT-CDO-BUSYSTATUS:FREE
+SUMMARY:Office hours
+END:VEVENT`,
+			pending: []string{
+				"slot office-hours 2026-11-04 15:00:00-16:00:00 FREQ=WEEKLY;BYDAY=WE",
+				"exception office-hours/20261111T140000Z 2026-11-11 15:00:00-2026-11-11 16:00:00 Office hours",
+			},
+		},
+		{
+			// The moved occurrence is taken out of the slot, which COUNT
+			// still repeats after now
+			name: "recurring free event with a moved occurrence",
+			components: `
+BEGIN:VEVENT
+UID:office-hours
+DTSTART;TZID=Europe/Berlin:20261007T090000
+DTEND;TZID=Europe/Berlin:20261007T100000
+RRULE:FREQ=WEEKLY;COUNT=10
+TRANSP:TRANSPARENT
+SUMMARY:Office hours
+END:VEVENT
+BEGIN:VEVENT
+UID:office-hours
+RECURRENCE-ID;TZID=Europe/Berlin:20261111T090000
+DTSTART;TZID=Europe/Berlin:20261112T140000
+DTEND;TZID=Europe/Berlin:20261112T150000
+TRANSP:TRANSPARENT
+SUMMARY:Office hours
+END:VEVENT`,
+			pending: []string{
+				"slot office-hours 2026-10-07 09:00:00-10:00:00 FREQ=WEEKLY;COUNT=10",
+				"exception office-hours/20261111T080000Z/replaced 2026-11-11 09:00:00-2026-11-11 10:00:00 Office hours",
+				"slot office-hours/20261111T080000Z 2026-11-12 14:00:00-15:00:00 ",
+			},
+		},
+		{
+			name: "busy events",
+			components: `
+BEGIN:VEVENT
+UID:dentist
+DTSTART:20261104T150000Z
+DURATION:PT1H
+SUMMARY:Dentist
+END:VEVENT
+BEGIN:VEVENT
+UID:conference
+DTSTART;VALUE=DATE:20261105
+DTEND;VALUE=DATE:20261107
+SUMMARY:Conference
+END:VEVENT
+BEGIN:VEVENT
+UID:night-shift
+DTSTART:20261104T210000Z
+DTEND:20261104T230000Z
+END:VEVENT`,
+			pending: []string{
+				"exception dentist 2026-11-04 16:00:00-2026-11-04 17:00:00 Dentist",
+				"exception conference 2026-11-05 00:00:00-2026-11-06 24:00:00 Conference",
+				"exception night-shift 2026-11-04 22:00:00-2026-11-04 24:00:00 ",
+			},
+		},
+		{
+			// From the day before now to the 14 days after it, less the
+			// EXDATE and the moved occurrence, which is an event of its own
+			name: "recurring busy event over the horizon",
+			components: `
+BEGIN:VEVENT
+UID:standup
+DTSTART;TZID=Europe/Berlin:20261019T093000
+DTEND;TZID=Europe/Berlin:20261019T094500
+RRULE:FREQ=WEEKLY;BYDAY=MO,TH
+EXDATE;TZID=Europe/Berlin:20261105T093000
+SUMMARY:Stand-up
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Berlin:20261109T093000
+DTSTART;TZID=Europe/Berlin:20261109T110000
+DTEND;TZID=Europe/Berlin:20261109T111500
+SUMMARY:Stand-up
+END:VEVENT`,
+			pending: []string{
+				"exception standup/20261102T083000Z 2026-11-02 09:30:00-2026-11-02 09:45:00 Stand-up",
+				"exception standup/20261112T083000Z 2026-11-12 09:30:00-2026-11-12 09:45:00 Stand-up",
+				"exception standup/20261109T083000Z 2026-11-09 11:00:00-2026-11-09 11:15:00 Stand-up",
+			},
+		},
+		{
+			name: "free/busy",
+			components: `
+BEGIN:VFREEBUSY
+UID:fb
+FREEBUSY:20261104T080000Z/PT1H,20261105T080000Z/20261105T100000Z
+FREEBUSY;FBTYPE=FREE:20261106T080000Z/PT1H
+END:VFREEBUSY`,
+			pending: []string{
+				"exception fb/20261104T080000Z 2026-11-04 09:00:00-2026-11-04 10:00:00 Busy",
+				"exception fb/20261105T080000Z 2026-11-05 09:00:00-2026-11-05 11:00:00 Busy",
+			},
+			skipped: []string{"fb: free periods aren't imported"},
+		},
+		{
+			name: "skipped entries",
+			components: `
+BEGIN:VEVENT
+UID:cancelled
+DTSTART:20261104T090000Z
+DTEND:20261104T100000Z
+STATUS:CANCELLED
+END:VEVENT
+BEGIN:VEVENT
+UID:past
+DTSTART:20261030T090000Z
+DTEND:20261030T100000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:ended-count
+DTSTART:20261005T090000Z
+DTEND:20261005T100000Z
+RRULE:FREQ=WEEKLY;COUNT=3
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:known
+DTSTART:20261104T090000Z
+DTEND:20261104T100000Z
+END:VEVENT
+BEGIN:VTODO
+UID:todo
+SUMMARY:Write feedback
+END:VTODO`,
+			imported: []string{"known"},
+			skipped: []string{
+				"cancelled: the event is cancelled",
+				"past: the event is in the past",
+				"ended-count: the event is in the past",
+				"known: already imported",
+				"todo: VTODO components aren't imported",
+			},
+		},
+		{
+			name: "invalid entries",
+			components: `
+BEGIN:VEVENT
+DTSTART:20261104T090000Z
+END:VEVENT
+BEGIN:VEVENT
+UID:no-start
+END:VEVENT
+BEGIN:VEVENT
+UID:overnight
+DTSTART:20261104T200000Z
+DTEND:20261105T080000Z
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:backwards
+DTSTART:20261104T100000Z
+DTEND:20261104T090000Z
+END:VEVENT`,
+			invalid: []string{
+				": UID is required",
+				"no-start: DTSTART is required",
+				"overnight: a free event must start and end on the same day",
+				"backwards: the exception must end after it starts",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			i := read(t, test.components, test.imported...)
+			for _, check := range []struct {
+				name      string
+				got, want []string
+			}{
+				{"pending", describe(i.Pending), test.pending},
+				{"skipped", describe(i.Report.Skipped), test.skipped},
+				{"invalid", describe(i.Report.Invalid), test.invalid},
+			} {
+				if check.want == nil {
+					check.want = []string{}
+				}
+				if !reflect.DeepEqual(check.got, check.want) {
+					t.Errorf("%s\n%s,\nwant\n%s", check.name, strings.Join(check.got, "\n"), strings.Join(check.want, "\n"))
+				}
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	long := strings.Repeat("a", MaxText-1) + "é"
+	if got := Truncate(long); got != strings.Repeat("a", MaxText-1) {
+		t.Errorf("Truncate cut a character: %q", got[MaxText-3:])
+	}
+	if got := Truncate("short"); got != "short" {
+		t.Errorf("Truncate(%q) = %q", "short", got)
+	}
+}
@@ -0,0 +1,240 @@
+// Package migrations keeps the database schema versioned. Every dialect has
+// its own directory of numbered migrations, embedded in the binary, and the
+// versions already applied are recorded in the schema_migrations table.
+//
+// A migration is a pair of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql. Statements are separated by a semicolon at the
+// end of a line and lines starting with "--" are comments.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed mysql postgres sqlite3
+var files embed.FS
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// New returns a Migrator with the migrations of the dialect
+// ("mysql", "postgres" or "sqlite3"), sorted by version.
+func New(db *sql.DB, dialect string) (*Migrator, error) {
+	migrations, err := Load(dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{DB: db, Migrations: migrations}, nil
+}
+
+func Load(dialect string) ([]Migration, error) {
+	names, err := fs.Glob(files, path.Join(dialect, "*.up.sql"))
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("migrations: no migrations for dialect %q", dialect)
+	}
+
+	migrations := []Migration{}
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".up.sql")
+		separator := strings.Index(base, "_")
+		if separator < 0 {
+			return nil, fmt.Errorf("migrations: %s has no version prefix", name)
+		}
+		version, err := strconv.Atoi(base[:separator])
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %s has an invalid version: %v", name, err)
+		}
+		up, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		down, err := files.ReadFile(strings.TrimSuffix(name, ".up.sql") + ".down.sql")
+		if err != nil {
+			return nil, fmt.Errorf("migrations: %s has no down migration", name)
+		}
+		migrations = append(migrations, Migration{
+			Version: version,
+			Name:    base[separator+1:],
+			Up:      string(up),
+			Down:    string(down),
+		})
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migrations: version %d is used twice", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// Up applies every pending migration in order and returns the ones applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	done := []Migration{}
+	for _, migration := range m.Migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		record := fmt.Sprintf("INSERT INTO schema_migrations (version, applied_at) VALUES (%d, CURRENT_TIMESTAMP)", migration.Version)
+		if err = m.run(migration.Up, record); err != nil {
+			return done, fmt.Errorf("migrations: applying %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the latest applied migration. It returns nil when there
+// is nothing left to revert.
+func (m *Migrator) Down() (*Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	for i := len(m.Migrations) - 1; i >= 0; i-- {
+		migration := m.Migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		record := fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %d", migration.Version)
+		if err = m.run(migration.Down, record); err != nil {
+			return nil, fmt.Errorf("migrations: reverting %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		return &migration, nil
+	}
+	return nil, nil
+}
+
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := []Status{}
+	for _, migration := range m.Migrations {
+		appliedAt, ok := applied[migration.Version]
+		statuses = append(statuses, Status{Migration: migration, Applied: ok, AppliedAt: appliedAt})
+	}
+	return statuses, nil
+}
+
+// Returns when each applied version was applied, creating the
+// schema_migrations table on first use
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	_, err := m.DB.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version INTEGER NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL)")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: creating schema_migrations: %v", err)
+	}
+	rows, err := m.DB.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: reading schema_migrations: %v", err)
+	}
+	defer rows.Close()
+	applied := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt timestamp
+		if err = rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("migrations: reading schema_migrations: %v", err)
+		}
+		applied[version] = appliedAt.Time
+	}
+	return applied, rows.Err()
+}
+
+// Runs the statements of a migration followed by the one recording it, in
+// a single transaction where the database supports transactional DDL
+func (m *Migrator) run(script, record string) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+	for _, statement := range append(statements(script), record) {
+		if _, err = tx.Exec(statement); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func statements(script string) []string {
+	statements := []string{}
+	current := []string{}
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, line)
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";"))
+			current = []string{}
+		}
+	}
+	if len(current) > 0 {
+		statements = append(statements, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+	return statements
+}
+
+// The drivers disagree on how they hand back timestamps: MySQL without
+// parseTime sends bytes, the others a time.Time
+type timestamp struct {
+	time.Time
+}
+
+func (t *timestamp) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	}
+	return fmt.Errorf("migrations: cannot read %T as a timestamp", value)
+}
+
+func (t *timestamp) parse(value string) error {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("migrations: invalid timestamp %q", value)
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `slots_weekdays`;
+DROP TABLE IF EXISTS `slots`;
+DROP TABLE IF EXISTS `interviewers`;
+DROP TABLE IF EXISTS `candidates`;
@@ -0,0 +1,31 @@
+-- Tables previously created by hand from database.sql
+
+CREATE TABLE IF NOT EXISTS `candidates` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(120) NOT NULL DEFAULT '',
+  `created_date` datetime NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `slots` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `candidate_id` int(11) DEFAULT NULL,
+  `interviewer_id` int(11) DEFAULT NULL,
+  `initial_time` time NOT NULL,
+  `final_time` time NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `interviewers` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(120) NOT NULL DEFAULT '',
+  `created_date` datetime NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `slots_weekdays` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `slot_id` int(11) NOT NULL,
+  `weekday` int(11) NOT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,12 @@
+ALTER TABLE `slots_weekdays`
+  DROP FOREIGN KEY `slots_weekdays_slot_id_fk`,
+  DROP INDEX `slots_weekdays_slot_id_idx`,
+  MODIFY `slot_id` int(11) NOT NULL;
+
+ALTER TABLE `slots`
+  DROP FOREIGN KEY `slots_candidate_id_fk`,
+  DROP FOREIGN KEY `slots_interviewer_id_fk`,
+  DROP INDEX `slots_candidate_id_idx`,
+  DROP INDEX `slots_interviewer_id_idx`,
+  MODIFY `candidate_id` int(11) DEFAULT NULL,
+  MODIFY `interviewer_id` int(11) DEFAULT NULL;
@@ -0,0 +1,17 @@
+-- Rows left behind by deletes made before the keys existed
+DELETE FROM `slots` WHERE `candidate_id` IS NOT NULL AND `candidate_id` NOT IN (SELECT `id` FROM `candidates`);
+DELETE FROM `slots` WHERE `interviewer_id` IS NOT NULL AND `interviewer_id` NOT IN (SELECT `id` FROM `interviewers`);
+DELETE FROM `slots_weekdays` WHERE `slot_id` NOT IN (SELECT `id` FROM `slots`);
+
+ALTER TABLE `slots`
+  MODIFY `candidate_id` int(11) unsigned DEFAULT NULL,
+  MODIFY `interviewer_id` int(11) unsigned DEFAULT NULL,
+  ADD INDEX `slots_candidate_id_idx` (`candidate_id`),
+  ADD INDEX `slots_interviewer_id_idx` (`interviewer_id`),
+  ADD CONSTRAINT `slots_candidate_id_fk` FOREIGN KEY (`candidate_id`) REFERENCES `candidates` (`id`) ON DELETE CASCADE,
+  ADD CONSTRAINT `slots_interviewer_id_fk` FOREIGN KEY (`interviewer_id`) REFERENCES `interviewers` (`id`) ON DELETE CASCADE;
+
+ALTER TABLE `slots_weekdays`
+  MODIFY `slot_id` int(11) unsigned NOT NULL,
+  ADD INDEX `slots_weekdays_slot_id_idx` (`slot_id`),
+  ADD CONSTRAINT `slots_weekdays_slot_id_fk` FOREIGN KEY (`slot_id`) REFERENCES `slots` (`id`) ON DELETE CASCADE;
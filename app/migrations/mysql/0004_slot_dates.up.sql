@@ -0,0 +1,2 @@
+-- One-off slots happen on their date instead of every week on their weekdays
+ALTER TABLE `slots` ADD COLUMN `date` date DEFAULT NULL AFTER `interviewer_id`;
@@ -0,0 +1,17 @@
+-- Periods in which a person is unavailable despite their slots, from the
+-- initial time of the from date to the final time of the to date
+CREATE TABLE IF NOT EXISTS `exceptions` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `candidate_id` int(11) unsigned DEFAULT NULL,
+  `interviewer_id` int(11) unsigned DEFAULT NULL,
+  `from_date` date NOT NULL,
+  `initial_time` time NOT NULL,
+  `to_date` date NOT NULL,
+  `final_time` time NOT NULL,
+  `reason` varchar(255) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  KEY `exceptions_candidate_id_idx` (`candidate_id`),
+  KEY `exceptions_interviewer_id_idx` (`interviewer_id`),
+  CONSTRAINT `exceptions_candidate_id_fk` FOREIGN KEY (`candidate_id`) REFERENCES `candidates` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `exceptions_interviewer_id_fk` FOREIGN KEY (`interviewer_id`) REFERENCES `interviewers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,25 @@
+-- Interviews booked for a candidate and one or more interviewers, with
+-- their start and end in UTC
+CREATE TABLE IF NOT EXISTS `bookings` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `candidate_id` int(11) unsigned NOT NULL,
+  `start_time` datetime NOT NULL,
+  `end_time` datetime NOT NULL,
+  `status` varchar(20) NOT NULL DEFAULT 'confirmed',
+  `created_date` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `bookings_candidate_id_idx` (`candidate_id`),
+  KEY `bookings_start_time_idx` (`start_time`),
+  CONSTRAINT `bookings_candidate_id_fk` FOREIGN KEY (`candidate_id`) REFERENCES `candidates` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `bookings_interviewers` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `booking_id` int(11) unsigned NOT NULL,
+  `interviewer_id` int(11) unsigned NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `bookings_interviewers_booking_id_idx` (`booking_id`),
+  KEY `bookings_interviewers_interviewer_id_idx` (`interviewer_id`),
+  CONSTRAINT `bookings_interviewers_booking_id_fk` FOREIGN KEY (`booking_id`) REFERENCES `bookings` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `bookings_interviewers_interviewer_id_fk` FOREIGN KEY (`interviewer_id`) REFERENCES `interviewers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
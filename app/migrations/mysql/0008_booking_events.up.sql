@@ -0,0 +1,19 @@
+-- Status changes of the bookings, with the interview times once changed
+CREATE TABLE IF NOT EXISTS `booking_events` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `booking_id` int(11) unsigned NOT NULL,
+  `status` varchar(20) NOT NULL,
+  `previous_status` varchar(20) NOT NULL DEFAULT '',
+  `start_time` datetime NOT NULL,
+  `end_time` datetime NOT NULL,
+  `created_date` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `booking_events_booking_id_idx` (`booking_id`),
+  CONSTRAINT `booking_events_booking_id_fk` FOREIGN KEY (`booking_id`) REFERENCES `bookings` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE INDEX `bookings_status_idx` ON `bookings` (`status`);
+
+-- The bookings made so far start their history when they were created
+INSERT INTO `booking_events` (`booking_id`, `status`, `start_time`, `end_time`, `created_date`)
+  SELECT `id`, `status`, `start_time`, `end_time`, `created_date` FROM `bookings`;
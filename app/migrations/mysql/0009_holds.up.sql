@@ -0,0 +1,25 @@
+-- Tentative reservations of the interviewers for a candidate, until
+-- expires_at, all times in UTC
+CREATE TABLE IF NOT EXISTS `holds` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `candidate_id` int(11) unsigned NOT NULL,
+  `start_time` datetime NOT NULL,
+  `end_time` datetime NOT NULL,
+  `expires_at` datetime NOT NULL,
+  `created_date` datetime NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `holds_candidate_id_idx` (`candidate_id`),
+  KEY `holds_expires_at_idx` (`expires_at`),
+  CONSTRAINT `holds_candidate_id_fk` FOREIGN KEY (`candidate_id`) REFERENCES `candidates` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `holds_interviewers` (
+  `id` int(11) unsigned NOT NULL AUTO_INCREMENT,
+  `hold_id` int(11) unsigned NOT NULL,
+  `interviewer_id` int(11) unsigned NOT NULL,
+  PRIMARY KEY (`id`),
+  KEY `holds_interviewers_hold_id_idx` (`hold_id`),
+  KEY `holds_interviewers_interviewer_id_idx` (`interviewer_id`),
+  CONSTRAINT `holds_interviewers_hold_id_fk` FOREIGN KEY (`hold_id`) REFERENCES `holds` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `holds_interviewers_interviewer_id_fk` FOREIGN KEY (`interviewer_id`) REFERENCES `interviewers` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
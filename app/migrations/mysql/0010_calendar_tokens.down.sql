@@ -0,0 +1,2 @@
+ALTER TABLE `interviewers` DROP COLUMN `calendar_token`;
+ALTER TABLE `candidates` DROP COLUMN `calendar_token`;
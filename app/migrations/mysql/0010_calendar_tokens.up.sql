@@ -0,0 +1,3 @@
+-- Secret of the iCalendar feed of each person, empty until one is created
+ALTER TABLE `candidates` ADD COLUMN `calendar_token` varchar(64) NOT NULL DEFAULT '' AFTER `time_zone`;
+ALTER TABLE `interviewers` ADD COLUMN `calendar_token` varchar(64) NOT NULL DEFAULT '' AFTER `time_zone`;
@@ -0,0 +1,2 @@
+ALTER TABLE `exceptions` DROP COLUMN `uid`;
+ALTER TABLE `slots` DROP COLUMN `uid`;
@@ -0,0 +1,4 @@
+-- UID of the iCalendar entry a slot or an exception was imported from, so
+-- that importing the same file again skips it
+ALTER TABLE `slots` ADD COLUMN `uid` varchar(255) NOT NULL DEFAULT '' AFTER `rrule`;
+ALTER TABLE `exceptions` ADD COLUMN `uid` varchar(255) NOT NULL DEFAULT '' AFTER `reason`;
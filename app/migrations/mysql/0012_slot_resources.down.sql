@@ -0,0 +1 @@
+ALTER TABLE `slots` DROP COLUMN `resource`;
@@ -0,0 +1,3 @@
+-- Name of the CalDAV resource a client created the slot under, when it
+-- isn't the one made of its UID
+ALTER TABLE `slots` ADD COLUMN `resource` varchar(255) NOT NULL DEFAULT '' AFTER `uid`;
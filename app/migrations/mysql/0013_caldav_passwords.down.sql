@@ -0,0 +1 @@
+ALTER TABLE `interviewers` DROP COLUMN `caldav_password`;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS candidates (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(120) NOT NULL DEFAULT '',
+  created_date TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS slots (
+  id SERIAL PRIMARY KEY,
+  candidate_id INTEGER DEFAULT NULL,
+  interviewer_id INTEGER DEFAULT NULL,
+  initial_time TIME NOT NULL,
+  final_time TIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS interviewers (
+  id SERIAL PRIMARY KEY,
+  name VARCHAR(120) NOT NULL DEFAULT '',
+  created_date TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS slots_weekdays (
+  id SERIAL PRIMARY KEY,
+  slot_id INTEGER NOT NULL,
+  weekday INTEGER NOT NULL
+);
@@ -0,0 +1,8 @@
+ALTER TABLE slots_weekdays DROP CONSTRAINT slots_weekdays_slot_id_fk;
+ALTER TABLE slots
+  DROP CONSTRAINT slots_candidate_id_fk,
+  DROP CONSTRAINT slots_interviewer_id_fk;
+
+DROP INDEX slots_weekdays_slot_id_idx;
+DROP INDEX slots_interviewer_id_idx;
+DROP INDEX slots_candidate_id_idx;
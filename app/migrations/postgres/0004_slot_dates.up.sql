@@ -0,0 +1,2 @@
+-- One-off slots happen on their date instead of every week on their weekdays
+ALTER TABLE slots ADD COLUMN date DATE;
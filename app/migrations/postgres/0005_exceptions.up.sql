@@ -0,0 +1,15 @@
+-- Periods in which a person is unavailable despite their slots, from the
+-- initial time of the from date to the final time of the to date
+CREATE TABLE IF NOT EXISTS exceptions (
+  id SERIAL PRIMARY KEY,
+  candidate_id INTEGER DEFAULT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+  interviewer_id INTEGER DEFAULT NULL REFERENCES interviewers (id) ON DELETE CASCADE,
+  from_date DATE NOT NULL,
+  initial_time TIME NOT NULL,
+  to_date DATE NOT NULL,
+  final_time TIME NOT NULL,
+  reason VARCHAR(255) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX exceptions_candidate_id_idx ON exceptions (candidate_id);
+CREATE INDEX exceptions_interviewer_id_idx ON exceptions (interviewer_id);
@@ -0,0 +1,21 @@
+-- Interviews booked for a candidate and one or more interviewers, with
+-- their start and end in UTC
+CREATE TABLE IF NOT EXISTS bookings (
+  id SERIAL PRIMARY KEY,
+  candidate_id INTEGER NOT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  status VARCHAR(20) NOT NULL DEFAULT 'confirmed',
+  created_date TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS bookings_interviewers (
+  id SERIAL PRIMARY KEY,
+  booking_id INTEGER NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+  interviewer_id INTEGER NOT NULL REFERENCES interviewers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX bookings_candidate_id_idx ON bookings (candidate_id);
+CREATE INDEX bookings_start_time_idx ON bookings (start_time);
+CREATE INDEX bookings_interviewers_booking_id_idx ON bookings_interviewers (booking_id);
+CREATE INDEX bookings_interviewers_interviewer_id_idx ON bookings_interviewers (interviewer_id);
@@ -0,0 +1,2 @@
+DROP INDEX bookings_status_idx;
+DROP TABLE IF EXISTS booking_events;
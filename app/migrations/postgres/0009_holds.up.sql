@@ -0,0 +1,21 @@
+-- Tentative reservations of the interviewers for a candidate, until
+-- expires_at, all times in UTC
+CREATE TABLE IF NOT EXISTS holds (
+  id SERIAL PRIMARY KEY,
+  candidate_id INTEGER NOT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+  start_time TIMESTAMP NOT NULL,
+  end_time TIMESTAMP NOT NULL,
+  expires_at TIMESTAMP NOT NULL,
+  created_date TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS holds_interviewers (
+  id SERIAL PRIMARY KEY,
+  hold_id INTEGER NOT NULL REFERENCES holds (id) ON DELETE CASCADE,
+  interviewer_id INTEGER NOT NULL REFERENCES interviewers (id) ON DELETE CASCADE
+);
+
+CREATE INDEX holds_candidate_id_idx ON holds (candidate_id);
+CREATE INDEX holds_expires_at_idx ON holds (expires_at);
+CREATE INDEX holds_interviewers_hold_id_idx ON holds_interviewers (hold_id);
+CREATE INDEX holds_interviewers_interviewer_id_idx ON holds_interviewers (interviewer_id);
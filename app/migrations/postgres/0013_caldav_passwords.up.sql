@@ -0,0 +1,3 @@
+-- Password of the CalDAV account of each interviewer, apart from the
+-- read-only feed token, empty until one is created
+ALTER TABLE interviewers ADD COLUMN caldav_password VARCHAR(64) NOT NULL DEFAULT '';
@@ -0,0 +1,27 @@
+-- Times are kept as TEXT so the driver hands them back exactly as MySQL does
+
+CREATE TABLE IF NOT EXISTS candidates (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(120) NOT NULL DEFAULT '',
+  created_date DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS slots (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  candidate_id INTEGER DEFAULT NULL,
+  interviewer_id INTEGER DEFAULT NULL,
+  initial_time TEXT NOT NULL,
+  final_time TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS interviewers (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(120) NOT NULL DEFAULT '',
+  created_date DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS slots_weekdays (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slot_id INTEGER NOT NULL,
+  weekday INTEGER NOT NULL
+);
@@ -0,0 +1,33 @@
+-- Rebuilds the tables without their foreign keys
+
+DROP INDEX slots_weekdays_slot_id_idx;
+DROP INDEX slots_interviewer_id_idx;
+DROP INDEX slots_candidate_id_idx;
+
+CREATE TABLE slots_weekdays_old (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slot_id INTEGER NOT NULL,
+  weekday INTEGER NOT NULL
+);
+INSERT INTO slots_weekdays_old (id, slot_id, weekday)
+  SELECT id, slot_id, weekday FROM slots_weekdays;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'slots_weekdays') WHERE name = 'slots_weekdays_old';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'slots_weekdays_old', seq FROM sqlite_sequence
+  WHERE name = 'slots_weekdays' AND NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'slots_weekdays_old');
+DROP TABLE slots_weekdays;
+ALTER TABLE slots_weekdays_old RENAME TO slots_weekdays;
+
+CREATE TABLE slots_old (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  candidate_id INTEGER DEFAULT NULL,
+  interviewer_id INTEGER DEFAULT NULL,
+  initial_time TEXT NOT NULL,
+  final_time TEXT NOT NULL
+);
+INSERT INTO slots_old (id, candidate_id, interviewer_id, initial_time, final_time)
+  SELECT id, candidate_id, interviewer_id, initial_time, final_time FROM slots;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'slots') WHERE name = 'slots_old';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'slots_old', seq FROM sqlite_sequence
+  WHERE name = 'slots' AND NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'slots_old');
+DROP TABLE slots;
+ALTER TABLE slots_old RENAME TO slots;
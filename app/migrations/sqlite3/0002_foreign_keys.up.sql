@@ -0,0 +1,38 @@
+-- SQLite can't add constraints to existing tables, so slots and
+-- slots_weekdays are rebuilt keeping their rows and id sequences
+
+DELETE FROM slots WHERE candidate_id IS NOT NULL AND candidate_id NOT IN (SELECT id FROM candidates);
+DELETE FROM slots WHERE interviewer_id IS NOT NULL AND interviewer_id NOT IN (SELECT id FROM interviewers);
+DELETE FROM slots_weekdays WHERE slot_id NOT IN (SELECT id FROM slots);
+
+CREATE TABLE slots_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  candidate_id INTEGER DEFAULT NULL REFERENCES candidates (id) ON DELETE CASCADE,
+  interviewer_id INTEGER DEFAULT NULL REFERENCES interviewers (id) ON DELETE CASCADE,
+  initial_time TEXT NOT NULL,
+  final_time TEXT NOT NULL
+);
+INSERT INTO slots_new (id, candidate_id, interviewer_id, initial_time, final_time)
+  SELECT id, candidate_id, interviewer_id, initial_time, final_time FROM slots;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'slots') WHERE name = 'slots_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'slots_new', seq FROM sqlite_sequence
+  WHERE name = 'slots' AND NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'slots_new');
+DROP TABLE slots;
+ALTER TABLE slots_new RENAME TO slots;
+
+CREATE TABLE slots_weekdays_new (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  slot_id INTEGER NOT NULL REFERENCES slots (id) ON DELETE CASCADE,
+  weekday INTEGER NOT NULL
+);
+INSERT INTO slots_weekdays_new (id, slot_id, weekday)
+  SELECT id, slot_id, weekday FROM slots_weekdays;
+UPDATE sqlite_sequence SET seq = (SELECT seq FROM sqlite_sequence WHERE name = 'slots_weekdays') WHERE name = 'slots_weekdays_new';
+INSERT INTO sqlite_sequence (name, seq) SELECT 'slots_weekdays_new', seq FROM sqlite_sequence
+  WHERE name = 'slots_weekdays' AND NOT EXISTS (SELECT 1 FROM sqlite_sequence WHERE name = 'slots_weekdays_new');
+DROP TABLE slots_weekdays;
+ALTER TABLE slots_weekdays_new RENAME TO slots_weekdays;
+
+CREATE INDEX slots_candidate_id_idx ON slots (candidate_id);
+CREATE INDEX slots_interviewer_id_idx ON slots (interviewer_id);
+CREATE INDEX slots_weekdays_slot_id_idx ON slots_weekdays (slot_id);
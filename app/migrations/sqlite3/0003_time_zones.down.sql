@@ -0,0 +1,2 @@
+ALTER TABLE interviewers DROP COLUMN time_zone;
+ALTER TABLE candidates DROP COLUMN time_zone;
@@ -0,0 +1,3 @@
+-- IANA time zone of the slots of each person
+ALTER TABLE candidates ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE interviewers ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
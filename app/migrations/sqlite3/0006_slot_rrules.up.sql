@@ -0,0 +1,2 @@
+-- RFC 5545 recurrence rule of the slots starting on their date
+ALTER TABLE slots ADD COLUMN rrule VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS bookings_interviewers;
+DROP TABLE IF EXISTS bookings;
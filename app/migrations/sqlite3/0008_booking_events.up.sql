@@ -0,0 +1,17 @@
+-- Status changes of the bookings, with the interview times once changed
+CREATE TABLE IF NOT EXISTS booking_events (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  booking_id INTEGER NOT NULL REFERENCES bookings (id) ON DELETE CASCADE,
+  status VARCHAR(20) NOT NULL,
+  previous_status VARCHAR(20) NOT NULL DEFAULT '',
+  start_time DATETIME NOT NULL,
+  end_time DATETIME NOT NULL,
+  created_date DATETIME NOT NULL
+);
+
+CREATE INDEX booking_events_booking_id_idx ON booking_events (booking_id);
+CREATE INDEX bookings_status_idx ON bookings (status);
+
+-- The bookings made so far start their history when they were created
+INSERT INTO booking_events (booking_id, status, start_time, end_time, created_date)
+  SELECT id, status, start_time, end_time, created_date FROM bookings;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS holds_interviewers;
+DROP TABLE IF EXISTS holds;
@@ -0,0 +1,161 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Booking statuses. New bookings are proposed or confirmed, the last three
+// are final.
+const (
+	BookingProposed    = "proposed"
+	BookingConfirmed   = "confirmed"
+	BookingRescheduled = "rescheduled"
+	BookingCompleted   = "completed"
+	BookingNoShow      = "no_show"
+	BookingCancelled   = "cancelled"
+)
+
+var BookingStatuses = []string{
+	BookingProposed,
+	BookingConfirmed,
+	BookingRescheduled,
+	BookingCompleted,
+	BookingNoShow,
+	BookingCancelled,
+}
+
+// The statuses each status can move to
+var transitions = map[string][]string{
+	BookingProposed:    {BookingConfirmed, BookingRescheduled, BookingCancelled},
+	BookingConfirmed:   {BookingRescheduled, BookingCompleted, BookingNoShow, BookingCancelled},
+	BookingRescheduled: {BookingConfirmed, BookingRescheduled, BookingCompleted, BookingNoShow, BookingCancelled},
+}
+
+// Booking is an interview of a candidate with one or more interviewers,
+// from Start to End.
+type Booking struct {
+	Id           int `json:",omitempty"`
+	Candidate    Candidate
+	Interviewers []Interviewer
+	Start        time.Time
+	End          time.Time
+	Status       string `json:",omitempty"`
+	// Every status change, oldest first. Only filled when reading a single
+	// booking.
+	History []BookingEvent `json:",omitempty"`
+}
+
+// BookingEvent records a status change of a booking, and its times once
+// changed.
+type BookingEvent struct {
+	Status         string
+	PreviousStatus string `json:",omitempty"`
+	Start          time.Time
+	End            time.Time
+	At             time.Time
+}
+
+// RescheduleRequest either asks for alternative times for a booking, from
+// the From to the To date, or moves it to Start.
+type RescheduleRequest struct {
+	// IANA time zone of the alternatives and of From and To, UTC by default
+	TimeZone string `json:",omitempty"`
+	Step     string `json:",omitempty"`
+	From     *Date  `json:",omitempty"`
+	To       *Date  `json:",omitempty"`
+	// The new time of the interview. End defaults to Start plus the
+	// current length of the interview.
+	Start *time.Time `json:",omitempty"`
+	End   *time.Time `json:",omitempty"`
+}
+
+// Active tells whether the booking still takes the time of its people.
+func (b Booking) Active() bool {
+	return b.Status != BookingCancelled
+}
+
+// Validate checks the booking has a candidate, distinct interviewers, lasts
+// at most a day and starts as proposed or confirmed.
+func (b Booking) Validate() error {
+	if err := validatePeople(b.Candidate, b.Interviewers); err != nil {
+		return err
+	}
+	if err := b.ValidateTimes(); err != nil {
+		return err
+	}
+	if b.Status != BookingProposed && b.Status != BookingConfirmed {
+		return fmt.Errorf("a new interview is either %s or %s", BookingProposed, BookingConfirmed)
+	}
+	return nil
+}
+
+// ValidateTimes checks the booking ends after it starts, within a day.
+func (b Booking) ValidateTimes() error {
+	return validateTimes(b.Start, b.End)
+}
+
+func validatePeople(candidate Candidate, interviewers []Interviewer) error {
+	if candidate.Id == 0 {
+		return errors.New("Candidate is required")
+	}
+	if len(interviewers) == 0 {
+		return errors.New("at least one interviewer is required")
+	}
+	seen := map[int]bool{}
+	for _, interviewer := range interviewers {
+		if seen[interviewer.Id] {
+			return fmt.Errorf("interviewer %d is given twice", interviewer.Id)
+		}
+		seen[interviewer.Id] = true
+	}
+	return nil
+}
+
+func validateTimes(start, end time.Time) error {
+	if start.IsZero() || !end.After(start) {
+		return errors.New("End must be after Start")
+	}
+	if end.Sub(start) > 24*time.Hour {
+		return errors.New("an interview can't last more than 24 hours")
+	}
+	return nil
+}
+
+// ValidStatus tells whether status is one of BookingStatuses.
+func ValidStatus(status string) bool {
+	for _, s := range BookingStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned for a status change the lifecycle of the
+// bookings doesn't allow.
+type TransitionError struct {
+	From string
+	To   string
+}
+
+func (e *TransitionError) Error() string {
+	allowed := transitions[e.From]
+	if len(allowed) == 0 {
+		return fmt.Sprintf("a %s interview can't change status anymore", e.From)
+	}
+	return fmt.Sprintf("a %s interview can't become %s, only %s", e.From, e.To, strings.Join(allowed, ", "))
+}
+
+// CheckTransition returns a *TransitionError unless a booking can move from
+// status from to status to.
+func CheckTransition(from, to string) error {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to}
+}
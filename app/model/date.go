@@ -0,0 +1,92 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Date is a calendar day, without a time of the day nor a time zone, written
+// as "2006-01-02".
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+const dateLayout = "2006-01-02"
+
+func ParseDate(value string) (Date, error) {
+	parsed, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return DateOf(parsed), nil
+}
+
+// DateOf returns the day of t in its own location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{year, month, day}
+}
+
+// In returns the midnight starting the day in location.
+func (d Date) In(location *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, location)
+}
+
+// AddDays returns the date days after d, or before it when negative.
+func (d Date) AddDays(days int) Date {
+	return DateOf(time.Date(d.Year, d.Month, d.Day+days, 0, 0, 0, 0, time.UTC))
+}
+
+func (d Date) Before(other Date) bool {
+	return d.In(time.UTC).Before(other.In(time.UTC))
+}
+
+func (d Date) Weekday() time.Weekday {
+	return d.In(time.UTC).Weekday()
+}
+
+func (d Date) String() string {
+	return d.In(time.UTC).Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("date must be a string such as \"2006-01-02\": %v", err)
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value stores the date as "2006-01-02", which every supported database
+// accepts for its date columns.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (d *Date) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case []byte:
+		*d, err = ParseDate(string(v))
+	case string:
+		*d, err = ParseDate(v)
+	case time.Time:
+		*d = DateOf(v)
+	default:
+		err = fmt.Errorf("cannot scan %T into a date", src)
+	}
+	return err
+}
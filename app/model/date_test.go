@@ -0,0 +1,95 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Date
+		invalid bool
+	}{
+		{value: "2026-11-04", want: Date{2026, time.November, 4}},
+		{value: "2028-02-29", want: Date{2028, time.February, 29}},
+		{value: "2026-02-29", invalid: true},
+		{value: "2026-13-01", invalid: true},
+		{value: "04/11/2026", invalid: true},
+		{value: "", invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := ParseDate(test.value)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("ParseDate(%q) = %v, want an error", test.value, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("ParseDate(%q) = %v, %v, want %v", test.value, got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestDateAddDays(t *testing.T) {
+	tests := []struct {
+		date Date
+		days int
+		want Date
+	}{
+		{Date{2026, time.November, 4}, 1, Date{2026, time.November, 5}},
+		{Date{2026, time.November, 30}, 1, Date{2026, time.December, 1}},
+		{Date{2026, time.December, 31}, 1, Date{2027, time.January, 1}},
+		{Date{2028, time.February, 28}, 1, Date{2028, time.February, 29}},
+		{Date{2026, time.March, 1}, -1, Date{2026, time.February, 28}},
+		{Date{2026, time.March, 29}, 366, Date{2027, time.March, 30}},
+	}
+	for _, test := range tests {
+		if got := test.date.AddDays(test.days); got != test.want {
+			t.Errorf("%v.AddDays(%d) = %v, want %v", test.date, test.days, got, test.want)
+		}
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The day Berlin moves to summer time lasts 23 hours
+	day := Date{2026, time.March, 29}
+	if length := day.AddDays(1).In(berlin).Sub(day.In(berlin)); length != 23*time.Hour {
+		t.Errorf("March 29th lasts %v in Berlin, want 23h", length)
+	}
+	if got := DateOf(day.In(berlin)); got != day {
+		t.Errorf("DateOf(In) = %v, want %v", got, day)
+	}
+}
+
+func TestDateCompare(t *testing.T) {
+	a, b := Date{2026, time.November, 4}, Date{2026, time.November, 5}
+	if !a.Before(b) || b.Before(a) || a.Before(a) {
+		t.Errorf("Before is wrong for %v and %v", a, b)
+	}
+	if a.Weekday() != time.Wednesday {
+		t.Errorf("%v is a %v, want Wednesday", a, a.Weekday())
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	data, err := json.Marshal(Date{2026, time.November, 4})
+	if err != nil || string(data) != `"2026-11-04"` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+	var parsed Date
+	if err = json.Unmarshal(data, &parsed); err != nil || parsed != (Date{2026, time.November, 4}) {
+		t.Errorf("Unmarshal = %v, %v", parsed, err)
+	}
+	if err = json.Unmarshal([]byte(`"2026-11-31"`), &parsed); err == nil {
+		t.Error("Unmarshal of November 31st succeeded")
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// FreeBusyResponse is the time of the candidates and interviewers asked
+// for, from the start of From to the end of To in TimeZone.
+type FreeBusyResponse struct {
+	From         Date
+	To           Date
+	TimeZone     string
+	Candidates   []FreeBusy
+	Interviewers []FreeBusy
+}
+
+// FreeBusy splits the time of a person in the periods they are Free, as
+// the slot matching finds them, Busy with an exception, an interview or a
+// hold, and Unavailable, out of their slots.
+type FreeBusy struct {
+	Id          int
+	Name        string
+	Free        []Period
+	Busy        []Period
+	Unavailable []Period
+}
+
+// Period is a span of time, written in RFC 3339.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// Hold tentatively reserves the time of the interviewers for a candidate,
+// until ExpiresAt. The other candidates aren't offered that time meanwhile.
+type Hold struct {
+	Id           int `json:",omitempty"`
+	Candidate    Candidate
+	Interviewers []Interviewer
+	Start        time.Time
+	End          time.Time
+	// How long the hold lasts, as "15m", only read when it is placed
+	TTL       string `json:",omitempty"`
+	ExpiresAt time.Time
+}
+
+// Validate checks the hold has a candidate, distinct interviewers and lasts
+// at most a day, like an interview.
+func (h Hold) Validate() error {
+	if err := validatePeople(h.Candidate, h.Interviewers); err != nil {
+		return err
+	}
+	return validateTimes(h.Start, h.End)
+}
+
+// Expired tells whether the hold is over at now.
+func (h Hold) Expired(now time.Time) bool {
+	return !now.Before(h.ExpiresAt)
+}
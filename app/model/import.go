@@ -0,0 +1,20 @@
+package model
+
+// ImportReport tells what became of the entries of an imported iCalendar
+// file: the slots and exceptions Created, the entries Skipped because they
+// were already imported or don't block any time, and the Invalid ones.
+type ImportReport struct {
+	Created []ImportEntry
+	Skipped []ImportEntry
+	Invalid []ImportEntry
+}
+
+// ImportEntry is an entry of an imported iCalendar file, with the slot or
+// the exception it created, or the Reason it didn't.
+type ImportEntry struct {
+	Uid       string     `json:",omitempty"`
+	Summary   string     `json:",omitempty"`
+	Slot      *Slot      `json:",omitempty"`
+	Exception *Exception `json:",omitempty"`
+	Reason    string     `json:",omitempty"`
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// LoopRequest asks for the itineraries of an interview loop: its Rounds in
+// order on the same day of the TimeZone. The Interviewers, if any, attend
+// every round, and the Duration is the one of the rounds without their own.
+type LoopRequest struct {
+	SlotMatchingRequest
+	Rounds []Round
+}
+
+// Round is a step of a loop, such as a technical interview, led by one of
+// its Interviewers.
+type Round struct {
+	Name         string `json:",omitempty"`
+	Interviewers []Interviewer
+	// Length of the round, as "45m", the Duration of the request by default
+	Duration string `json:",omitempty"`
+	// Least time between the end of the previous round and the start of
+	// this one, as "15m", none by default
+	Break string `json:",omitempty"`
+}
+
+// Validate checks the loop has a candidate and rounds, and that no
+// interviewer is given twice in a round or is in a round while attending
+// all of them.
+func (l LoopRequest) Validate() error {
+	if l.Candidate.Id == 0 {
+		return errors.New("Candidate is required")
+	}
+	if len(l.Rounds) == 0 {
+		return errors.New("at least one round is required")
+	}
+	everyRound := map[int]bool{}
+	for _, interviewer := range l.Interviewers {
+		if everyRound[interviewer.Id] {
+			return fmt.Errorf("interviewer %d is given twice", interviewer.Id)
+		}
+		everyRound[interviewer.Id] = true
+	}
+	for number, round := range l.Rounds {
+		if len(round.Interviewers) == 0 {
+			return fmt.Errorf("round %d needs at least one interviewer", number+1)
+		}
+		seen := map[int]bool{}
+		for _, interviewer := range round.Interviewers {
+			if seen[interviewer.Id] {
+				return fmt.Errorf("interviewer %d is given twice in round %d", interviewer.Id, number+1)
+			}
+			if everyRound[interviewer.Id] {
+				return fmt.Errorf("interviewer %d of round %d already attends every round", interviewer.Id, number+1)
+			}
+			seen[interviewer.Id] = true
+		}
+	}
+	return nil
+}
+
+// Itinerary is a feasible day of a loop, from the Start of its first round
+// to the End of its last one, in the time zone of the request.
+type Itinerary struct {
+	Start  time.Time
+	End    time.Time
+	Rounds []RoundAssignment
+}
+
+// RoundAssignment is a round of an itinerary, with the interviewer leading
+// it and its times.
+type RoundAssignment struct {
+	Name        string `json:",omitempty"`
+	Interviewer Interviewer
+	Start       time.Time
+	End         time.Time
+}
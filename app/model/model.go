@@ -1,28 +1,185 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/rrule"
+)
 
 type Candidate struct {
 	Id   int    `json:",omitempty"`
 	Name string `json:",omitempty"`
+	// IANA time zone of the candidate's slots, such as "America/Sao_Paulo"
+	TimeZone string `json:",omitempty"`
 }
 
 type Interviewer struct {
 	Id   int    `json:",omitempty"`
 	Name string `json:",omitempty"`
+	// IANA time zone of the interviewer's slots, such as "Europe/Berlin"
+	TimeZone string `json:",omitempty"`
+}
+
+// People without a time zone are in UTC
+const DefaultTimeZone = "UTC"
+
+// LoadLocation loads an IANA time zone, "" being DefaultTimeZone. Unlike
+// time.LoadLocation it refuses "Local", which depends on the server.
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = DefaultTimeZone
+	}
+	if name == "Local" {
+		return nil, errors.New("invalid time zone \"Local\", use an IANA name such as \"Europe/Lisbon\"")
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q, use an IANA name such as \"Europe/Lisbon\"", name)
+	}
+	return location, nil
 }
 
+// A slot either repeats every week on its Weekdays, happens once on its
+// Date, or follows its RFC 5545 RRule starting on its Date. Weekdays [1, 3]
+// are a shorthand for "FREQ=WEEKLY;BYDAY=MO,WE".
 type Slot struct {
-	Id          int            `json:",omitempty"`
-	PersonId    int            `json:",omitempty"`
-	InitialTime string         `json:",omitempty"`
-	FinalTime   string         `json:",omitempty"`
+	Id          int   `json:",omitempty"`
+	PersonId    int   `json:",omitempty"`
+	Date        *Date `json:",omitempty"`
+	InitialTime TimeOfDay
+	FinalTime   TimeOfDay
 	Weekdays    []time.Weekday `json:",omitempty"`
+	RRule       string         `json:",omitempty"`
+	// UID of the iCalendar entry the slot was imported from
+	Uid string `json:",omitempty"`
+	// Name of the CalDAV resource a client created the slot under, when it
+	// isn't the one made of the UID
+	Resource string `json:"-"`
+}
+
+// SetDefaults takes the Date from the DTSTART of the RRule when it has one
+// and the Date was left out.
+func (s *Slot) SetDefaults() {
+	if s.RRule == "" || s.Date != nil {
+		return
+	}
+	if rule, err := rrule.Parse(s.RRule); err == nil && !rule.Start.IsZero() {
+		date := DateOf(rule.Start)
+		s.Date = &date
+	}
+}
+
+// Validate checks the slot lasts some time on valid weekdays, or follows a
+// valid RRule.
+func (s Slot) Validate() error {
+	if s.FinalTime <= s.InitialTime {
+		return errors.New("FinalTime must be after InitialTime")
+	}
+	if s.Date != nil && len(s.Weekdays) > 0 {
+		return errors.New("a slot has either a Date or Weekdays, not both")
+	}
+	if len(s.RRule) > 255 {
+		return errors.New("RRule can't be longer than 255 characters")
+	}
+	if s.RRule != "" {
+		if _, err := s.Rule(time.UTC); err != nil {
+			return err
+		}
+	}
+	for _, weekday := range s.Weekdays {
+		if weekday < time.Sunday || weekday > time.Saturday {
+			return fmt.Errorf("invalid weekday %d, use 0 (Sunday) to 6 (Saturday)", weekday)
+		}
+	}
+	return nil
+}
+
+// Rule parses the RRule of the slot for the person's location, checking it
+// starts on the slot Date.
+func (s Slot) Rule(location *time.Location) (*rrule.Rule, error) {
+	rule, err := rrule.ParseIn(s.RRule, location)
+	if err != nil {
+		return nil, err
+	}
+	if s.Date == nil {
+		return nil, errors.New("a slot with an RRule needs the Date of its first occurrence, or a DTSTART")
+	}
+	if !rule.Start.IsZero() && DateOf(rule.Start) != *s.Date {
+		return nil, errors.New("the DTSTART of the RRule must be the slot Date")
+	}
+	return rule, nil
+}
+
+// An exception makes a person unavailable despite their slots, from the
+// InitialTime of From to the FinalTime of To, in the person's time zone.
+// To defaults to From and FinalTime to the end of the day, so that
+// {"From": "2026-12-20", "To": "2027-01-05"} covers both days entirely.
+type Exception struct {
+	Id          int `json:",omitempty"`
+	PersonId    int `json:",omitempty"`
+	From        Date
+	InitialTime TimeOfDay
+	To          Date
+	FinalTime   TimeOfDay
+	Reason      string `json:",omitempty"`
+	// UID of the iCalendar entry the exception was imported from
+	Uid string `json:",omitempty"`
+}
+
+// SetDefaults fills To and FinalTime when they were left out.
+func (e *Exception) SetDefaults() {
+	if e.To == (Date{}) {
+		e.To = e.From
+	}
+	if e.FinalTime == 0 {
+		e.FinalTime = EndOfDay
+	}
+}
+
+// Validate checks the exception has a From date and ends after it starts.
+func (e Exception) Validate() error {
+	if e.From == (Date{}) {
+		return errors.New("From is required")
+	}
+	if !e.Start(time.UTC).Before(e.End(time.UTC)) {
+		return errors.New("the exception must end after it starts")
+	}
+	return nil
+}
+
+// Start returns when the exception starts in the person's location.
+func (e Exception) Start(location *time.Location) time.Time {
+	return e.InitialTime.On(e.From, location)
+}
+
+// End returns when the exception ends in the person's location.
+func (e Exception) End(location *time.Location) time.Time {
+	return e.FinalTime.On(e.To, location)
 }
 
 type SlotMatchingRequest struct {
 	Candidate    Candidate     `json:",omitempty"`
 	Interviewers []Interviewer `json:",omitempty"`
+	// IANA time zone of the returned slots, UTC by default
+	TimeZone string `json:",omitempty"`
+	// Length of the interview and granularity of its start time, as
+	// "30m" or "1h30m". They default to the matching configuration.
+	Duration string `json:",omitempty"`
+	Step     string `json:",omitempty"`
+	// Dates of the interview, both included. From defaults to today and To
+	// to the configured horizon after From. Without both the weekly matches
+	// are returned, ignoring the dated slots.
+	From *Date `json:",omitempty"`
+	To   *Date `json:",omitempty"`
+}
+
+// InterviewOption is a concrete time for an interview, written in RFC 3339
+// in the time zone of the request.
+type InterviewOption struct {
+	Start time.Time
+	End   time.Time
 }
 
 type SlotMatchingResponse struct {
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a time of the day with second precision, counted in seconds
+// from midnight. It goes up to 24:00:00 so that a slot can last until the
+// end of the day.
+type TimeOfDay int
+
+const EndOfDay = TimeOfDay(24 * 60 * 60)
+
+// ParseTimeOfDay accepts "15:04" and "15:04:05".
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 && len(parts) != 3 {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM or HH:MM:SS", value)
+	}
+	fields := [3]int{}
+	for i, part := range parts {
+		if len(part) != 2 || part[0] < '0' || part[0] > '9' || part[1] < '0' || part[1] > '9' {
+			return 0, fmt.Errorf("invalid time of day %q, expected HH:MM or HH:MM:SS", value)
+		}
+		fields[i] = int(part[0]-'0')*10 + int(part[1]-'0')
+	}
+	hours, minutes, seconds := fields[0], fields[1], fields[2]
+	if minutes > 59 || seconds > 59 {
+		return 0, fmt.Errorf("invalid time of day %q", value)
+	}
+	t := TimeOfDay(hours*3600 + minutes*60 + seconds)
+	if t > EndOfDay {
+		return 0, fmt.Errorf("invalid time of day %q, it is past 24:00:00", value)
+	}
+	return t, nil
+}
+
+// NewTimeOfDay returns the time of the day that offset is from midnight,
+// dropping fractions of a second.
+func NewTimeOfDay(offset time.Duration) TimeOfDay {
+	return TimeOfDay(offset / time.Second)
+}
+
+// Duration returns the offset from midnight.
+func (t TimeOfDay) Duration() time.Duration {
+	return time.Duration(t) * time.Second
+}
+
+// On returns the time t of the wall clock of date in location, 24:00:00
+// being the next midnight.
+func (t TimeOfDay) On(date Date, location *time.Location) time.Time {
+	return time.Date(date.Year, date.Month, date.Day, 0, 0, int(t), 0, location)
+}
+
+func (t TimeOfDay) String() string {
+	return fmt.Sprintf("%02d:%02d:%02d", t/3600, t/60%60, t%60)
+}
+
+func (t TimeOfDay) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *TimeOfDay) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("time of day must be a string such as \"09:00\": %v", err)
+	}
+	parsed, err := ParseTimeOfDay(value)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
+
+// Value stores the time as "15:04:05", which every supported database
+// accepts for its time columns.
+func (t TimeOfDay) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+func (t *TimeOfDay) Scan(src interface{}) error {
+	var err error
+	switch v := src.(type) {
+	case []byte:
+		*t, err = ParseTimeOfDay(string(v))
+	case string:
+		*t, err = ParseTimeOfDay(v)
+	case time.Time:
+		// Drivers parsing time columns put them on January 1st of year 0,
+		// 24:00:00 being midnight of the 2nd
+		midnight := time.Date(v.Year(), v.Month(), 1, 0, 0, 0, 0, v.Location())
+		*t = NewTimeOfDay(v.Sub(midnight))
+	default:
+		err = fmt.Errorf("cannot scan %T into a time of day", src)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TimeOfDay
+		invalid bool
+	}{
+		{value: "00:00", want: 0},
+		{value: "09:30", want: 9*3600 + 30*60},
+		{value: "09:30:15", want: 9*3600 + 30*60 + 15},
+		{value: "24:00", want: EndOfDay},
+		{value: "24:00:01", invalid: true},
+		{value: "25:00", invalid: true},
+		{value: "09:60", invalid: true},
+		{value: "09:00:60", invalid: true},
+		{value: "9:30", invalid: true},
+		{value: "09h30", invalid: true},
+		{value: "09:30:00:00", invalid: true},
+		{value: "", invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := ParseTimeOfDay(test.value)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("ParseTimeOfDay(%q) = %v, want an error", test.value, got)
+				}
+				return
+			}
+			if err != nil || got != test.want {
+				t.Errorf("ParseTimeOfDay(%q) = %v, %v, want %v", test.value, got, err, test.want)
+			}
+		})
+	}
+}
+
+func TestTimeOfDayOn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := Date{2026, time.November, 4}
+	if got, want := TimeOfDay(9*3600).On(day, berlin), time.Date(2026, time.November, 4, 8, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("On = %v, want %v", got, want)
+	}
+	if got, want := EndOfDay.On(day, berlin), time.Date(2026, time.November, 4, 23, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("EndOfDay.On = %v, want %v", got, want)
+	}
+}
+
+func TestTimeOfDayJSON(t *testing.T) {
+	data, err := json.Marshal(TimeOfDay(9*3600 + 5))
+	if err != nil || string(data) != `"09:00:05"` {
+		t.Fatalf("Marshal = %s, %v", data, err)
+	}
+	var parsed TimeOfDay
+	if err = json.Unmarshal(data, &parsed); err != nil || parsed != 9*3600+5 {
+		t.Errorf("Unmarshal = %v, %v", parsed, err)
+	}
+	if err = json.Unmarshal([]byte(`900`), &parsed); err == nil {
+		t.Error("Unmarshal of a number succeeded")
+	}
+}
+
+func TestTimeOfDayScan(t *testing.T) {
+	tests := []struct {
+		name string
+		src  interface{}
+		want TimeOfDay
+	}{
+		{"string", "09:30:00", 9*3600 + 30*60},
+		{"bytes", []byte("17:00:00"), 17 * 3600},
+		{"time", time.Date(0, time.January, 1, 9, 30, 0, 0, time.UTC), 9*3600 + 30*60},
+		{"end of day", time.Date(0, time.January, 2, 0, 0, 0, 0, time.UTC), EndOfDay},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got TimeOfDay
+			if err := got.Scan(test.src); err != nil || got != test.want {
+				t.Errorf("Scan = %v, %v, want %v", got, err, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,678 @@
+package routes
+
+import (
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/dav"
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/imports"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/rrule"
+	"github.com/paulofeitor/kilabs-api/app/store"
+)
+
+// Each interviewer has a CalDAV principal at /caldav/interviewer/:id/ holding
+// a single calendar collection, /caldav/interviewer/:id/calendar/, with their
+// slots and interviews. The clients sign in with the interviewer id and
+// their CalDAV password, which is apart from the read-only feed token as
+// anyone with the feed URL has it. Events written by the clients become
+// slots, while the interviews are read only.
+
+const davRealm = "KI Labs interviews"
+
+const davMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+
+// A slot or an interview of the calendar collection
+type davResource struct {
+	name  string
+	event *ical.Component
+	// Nil for the interviews
+	slot *model.Slot
+	etag string
+}
+
+// ResetCalDAVPassword creates the CalDAV password of the interviewer,
+// replacing the previous one, and answers with the credentials and the URL
+// of the calendar collection
+func ResetCalDAVPassword(passwords store.CalendarTokenStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewerId, _ := strconv.Atoi(ps.ByName("interviewer_id"))
+	password, ok := newSecret(w)
+	if !ok {
+		return
+	}
+	if err := passwords.SetCalDAVPassword(interviewerId, password); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"User":     strconv.Itoa(interviewerId),
+		"Password": password,
+		"URL":      baseURL(r) + davCalendar(interviewerId),
+	})
+}
+
+// CalDAVOptions tells the clients the collections support CalDAV.
+func CalDAVOptions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	w.Header().Set("Allow", davMethods)
+	w.WriteHeader(http.StatusOK)
+}
+
+// CalDAVWellKnown sends the clients to the principal of the interviewer
+// they sign in as.
+func CalDAVWellKnown(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	user, _, ok := r.BasicAuth()
+	interviewerId, err := strconv.Atoi(user)
+	if !ok || err != nil {
+		davChallenge(w)
+		return
+	}
+	http.Redirect(w, r, davPrincipal(interviewerId), http.StatusMovedPermanently)
+}
+
+// CalDAVPropfind answers with the properties of the principal, of the
+// calendar collection or of one of its events, and of their children down
+// to the Depth header.
+func CalDAVPropfind(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewer, ok := davAuthenticate(s, w, r, ps)
+	if !ok {
+		return
+	}
+	request, err := dav.ParseRequest(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	depth := r.Header.Get("Depth")
+	now := time.Now()
+
+	responses := []dav.Response{}
+	calendar := strings.HasSuffix(r.URL.Path, "/calendar/")
+	if name := ps.ByName("resource"); name != "" {
+		resource, err := findDavResource(s, interviewer, name, now)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		responses = append(responses, davPropstat(request, r.URL.Path, resource.properties(interviewer, false)))
+	} else {
+		if !calendar {
+			responses = append(responses, davPropstat(request, davPrincipal(interviewer.Id), principalProperties(interviewer)))
+		}
+		// The principal holds the calendar, which holds the events
+		if calendar || depth != "0" {
+			resources, err := davResources(s, interviewer, now)
+			if err != nil {
+				writeStoreError(w, err)
+				return
+			}
+			responses = append(responses, davPropstat(request, davCalendar(interviewer.Id), calendarProperties(interviewer, resources)))
+			for i := 0; calendar && depth != "0" && i < len(resources); i++ {
+				responses = append(responses, davPropstat(request, davCalendar(interviewer.Id)+resources[i].name, resources[i].properties(interviewer, false)))
+			}
+		}
+	}
+	if err = dav.WriteMultistatus(w, responses); err != nil {
+		log.Println("CalDAV Write Error ::", err.Error())
+	}
+}
+
+// CalDAVReport answers the calendar-multiget and calendar-query reports of
+// the calendar collection. The filters of the queries are ignored, the
+// clients filtering the events themselves.
+func CalDAVReport(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewer, ok := davAuthenticate(s, w, r, ps)
+	if !ok {
+		return
+	}
+	request, err := dav.ParseRequest(r.Body)
+	defer r.Body.Close()
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if request.Name.Space != dav.CalDAV || (request.Name.Local != "calendar-multiget" && request.Name.Local != "calendar-query") {
+		dav.WriteError(w, http.StatusForbidden, xml.Name{Space: dav.NS, Local: "supported-report"})
+		return
+	}
+	resources, err := davResources(s, interviewer, time.Now())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	calendar := davCalendar(interviewer.Id)
+	responses := []dav.Response{}
+	if request.Name.Local == "calendar-query" {
+		for _, resource := range resources {
+			responses = append(responses, davPropstat(request, calendar+resource.name, resource.properties(interviewer, true)))
+		}
+	}
+	for _, href := range request.Hrefs {
+		name := href[strings.LastIndex(href, "/")+1:]
+		response := dav.Response{Href: href, Status: http.StatusNotFound}
+		for _, resource := range resources {
+			if resource.name == name {
+				response = davPropstat(request, href, resource.properties(interviewer, true))
+			}
+		}
+		responses = append(responses, response)
+	}
+	if err = dav.WriteMultistatus(w, responses); err != nil {
+		log.Println("CalDAV Write Error ::", err.Error())
+	}
+}
+
+// CalDAVGet answers with an event of the calendar collection.
+func CalDAVGet(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewer, ok := davAuthenticate(s, w, r, ps)
+	if !ok {
+		return
+	}
+	resource, err := findDavResource(s, interviewer, ps.ByName("resource"), time.Now())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("ETag", resource.etag)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err = w.Write([]byte(resource.calendarData(interviewer))); err != nil {
+		log.Println("CalDAV Write Error ::", err.Error())
+	}
+}
+
+// CalDAVPut writes an event of a client back as a slot of the interviewer,
+// creating it under the name of the request or updating the slot of the
+// resource. The EXDATEs and the moved occurrences of a recurring event
+// become exceptions of the slot, the moved occurrences becoming slots of
+// their own. Everything is written at once, or nothing is.
+func CalDAVPut(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewer, ok := davAuthenticate(s, w, r, ps)
+	if !ok {
+		return
+	}
+	location, err := model.LoadLocation(interviewer.TimeZone)
+	if err != nil {
+		log.Println("Time Zone Error ::", err.Error())
+		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+	now := time.Now()
+	resources, err := davResources(s, interviewer, now)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	name := ps.ByName("resource")
+	var existing *davResource
+	for i := range resources {
+		if resources[i].name == name {
+			existing = &resources[i]
+		}
+	}
+	if !davPreconditions(w, r, existing) {
+		return
+	}
+	if existing != nil && existing.slot == nil {
+		dav.WriteError(w, http.StatusForbidden, xml.Name{Space: dav.NS, Local: "need-privileges"})
+		return
+	}
+	if len(name) > imports.MaxText {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("resource names can't be longer than %d characters", imports.MaxText))
+		return
+	}
+
+	calendar, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	defer r.Body.Close()
+	if err == nil && calendar.Name != "VCALENDAR" {
+		err = fmt.Errorf("expected a VCALENDAR, not a %s", calendar.Name)
+	}
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		dav.WriteError(w, http.StatusBadRequest, xml.Name{Space: dav.CalDAV, Local: "valid-calendar-data"})
+		return
+	}
+	slots, err := davSlots(calendar, location)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		dav.WriteError(w, http.StatusForbidden, xml.Name{Space: dav.CalDAV, Local: "valid-calendar-object-resource"})
+		return
+	}
+	// The UID of an event belongs to a single resource
+	for _, resource := range resources {
+		if resource.uid() == slots[0].Uid && (existing == nil || resource.name != existing.name) {
+			dav.WriteError(w, http.StatusForbidden, xml.Name{Space: dav.CalDAV, Local: "no-uid-conflict"})
+			return
+		}
+	}
+
+	master := &slots[0]
+	master.PersonId = interviewer.Id
+	if existing != nil {
+		master.Id, master.Uid, master.Resource = existing.slot.Id, existing.slot.Uid, existing.slot.Resource
+		keepWeekdays(master, *existing.slot)
+	} else if name != davName(master.Uid) {
+		master.Resource = name
+	}
+	// The moved occurrences and the exceptions of the slot are replaced
+	write := store.CalendarWrite{Owner: store.InterviewerOwner, PersonId: interviewer.Id, Replaced: []string{slotUid(*master)}}
+	for i := range slots {
+		write.Slots = append(write.Slots, &slots[i])
+	}
+	excluded := davExcluded(calendar, location, *master)
+	for i := range excluded {
+		write.Exceptions = append(write.Exceptions, &excluded[i])
+	}
+	if err = s.WriteCalendar(write); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	// The ETag is the one of the event as stored, which GET answers with
+	if written, err := findDavResource(s, interviewer, name, now); err == nil {
+		w.Header().Set("ETag", written.etag)
+	}
+	if existing != nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+// CalDAVDelete deletes the slot of an event, with its exceptions and moved
+// occurrences.
+func CalDAVDelete(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewer, ok := davAuthenticate(s, w, r, ps)
+	if !ok {
+		return
+	}
+	resource, err := findDavResource(s, interviewer, ps.ByName("resource"), time.Now())
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if !davPreconditions(w, r, &resource) {
+		return
+	}
+	if resource.slot == nil {
+		dav.WriteError(w, http.StatusForbidden, xml.Name{Space: dav.NS, Local: "need-privileges"})
+		return
+	}
+	write := store.CalendarWrite{Owner: store.InterviewerOwner, PersonId: interviewer.Id, Deleted: []int{resource.slot.Id}, Replaced: []string{resource.uid()}}
+	if err = s.WriteCalendar(write); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Checks the Basic credentials are the id and the CalDAV password of the
+// interviewer of the path, challenging the client otherwise
+func davAuthenticate(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) (model.Interviewer, bool) {
+	interviewerId, _ := strconv.Atoi(ps.ByName("interviewer_id"))
+	user, password, ok := r.BasicAuth()
+	if !ok || user != strconv.Itoa(interviewerId) {
+		davChallenge(w)
+		return model.Interviewer{}, false
+	}
+	expected, err := s.GetCalDAVPassword(interviewerId)
+	if err != nil && err != store.ErrNotFound {
+		writeStoreError(w, err)
+		return model.Interviewer{}, false
+	}
+	if expected == "" || subtle.ConstantTimeCompare([]byte(password), []byte(expected)) != 1 {
+		davChallenge(w)
+		return model.Interviewer{}, false
+	}
+	interviewer, err := s.GetInterviewer(interviewerId)
+	if err != nil {
+		writeStoreError(w, err)
+		return interviewer, false
+	}
+	return interviewer, true
+}
+
+func davChallenge(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", davRealm))
+	writeError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+}
+
+// Checks the If-Match and If-None-Match headers against the resource, nil
+// when there is none, answering with a 412 when they fail
+func davPreconditions(w http.ResponseWriter, r *http.Request, resource *davResource) bool {
+	ifMatch, ifNoneMatch := r.Header.Get("If-Match"), r.Header.Get("If-None-Match")
+	failed := (ifNoneMatch == "*" && resource != nil) ||
+		(ifMatch != "" && (resource == nil || (ifMatch != "*" && ifMatch != resource.etag)))
+	if failed {
+		writeError(w, http.StatusPreconditionFailed, http.StatusText(http.StatusPreconditionFailed))
+		return false
+	}
+	if resource == nil && r.Method == http.MethodDelete {
+		writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return false
+	}
+	return true
+}
+
+func davPrincipal(interviewerId int) string {
+	return fmt.Sprintf("/caldav/interviewer/%d/", interviewerId)
+}
+
+func davCalendar(interviewerId int) string {
+	return davPrincipal(interviewerId) + "calendar/"
+}
+
+// The properties asked for by the request among the known ones
+func davPropstat(request dav.Request, href string, known map[xml.Name]string) dav.Response {
+	response := dav.Response{Href: href}
+	if request.AllProps {
+		for _, name := range davPropertyOrder {
+			if value, ok := known[name]; ok && name.Local != "calendar-data" {
+				response.Found = append(response.Found, dav.Property{Name: name, Value: value})
+			}
+		}
+	}
+	for _, name := range request.Props {
+		if value, ok := known[name]; ok {
+			response.Found = append(response.Found, dav.Property{Name: name, Value: value})
+		} else {
+			response.NotFound = append(response.NotFound, name)
+		}
+	}
+	return response
+}
+
+// Order of the properties of an allprop
+var davPropertyOrder = []xml.Name{
+	{Space: dav.NS, Local: "resourcetype"},
+	{Space: dav.NS, Local: "displayname"},
+	{Space: dav.NS, Local: "getetag"},
+	{Space: dav.NS, Local: "getcontenttype"},
+	{Space: dav.CalendarServer, Local: "getctag"},
+}
+
+func principalProperties(interviewer model.Interviewer) map[xml.Name]string {
+	principal := dav.Href(davPrincipal(interviewer.Id))
+	return map[xml.Name]string{
+		{Space: dav.NS, Local: "resourcetype"}:           "<D:collection/><D:principal/>",
+		{Space: dav.NS, Local: "displayname"}:            dav.Text(interviewer.Name),
+		{Space: dav.NS, Local: "current-user-principal"}: principal,
+		{Space: dav.NS, Local: "principal-URL"}:          principal,
+		{Space: dav.CalDAV, Local: "calendar-home-set"}:  principal,
+	}
+}
+
+func calendarProperties(interviewer model.Interviewer, resources []davResource) map[xml.Name]string {
+	// The ctag changes with any of the events
+	ctag := sha1.New()
+	for _, resource := range resources {
+		ctag.Write([]byte(resource.name + resource.etag))
+	}
+	return map[xml.Name]string{
+		{Space: dav.NS, Local: "resourcetype"}:                         "<D:collection/><C:calendar/>",
+		{Space: dav.NS, Local: "displayname"}:                          dav.Text(interviewer.Name + " - KI Labs interviews"),
+		{Space: dav.NS, Local: "current-user-principal"}:               dav.Href(davPrincipal(interviewer.Id)),
+		{Space: dav.NS, Local: "owner"}:                                dav.Href(davPrincipal(interviewer.Id)),
+		{Space: dav.NS, Local: "current-user-privilege-set"}:           "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>",
+		{Space: dav.NS, Local: "supported-report-set"}:                 "<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report><D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>",
+		{Space: dav.CalDAV, Local: "supported-calendar-component-set"}: `<C:comp name="VEVENT"/>`,
+		{Space: dav.CalendarServer, Local: "getctag"}:                  hex.EncodeToString(ctag.Sum(nil)),
+	}
+}
+
+// The properties of an event, with its calendar-data when asked to
+func (d davResource) properties(interviewer model.Interviewer, calendarData bool) map[xml.Name]string {
+	properties := map[xml.Name]string{
+		{Space: dav.NS, Local: "resourcetype"}:   "",
+		{Space: dav.NS, Local: "getetag"}:        dav.Text(d.etag),
+		{Space: dav.NS, Local: "getcontenttype"}: "text/calendar; charset=utf-8; component=vevent",
+	}
+	if calendarData {
+		properties[xml.Name{Space: dav.CalDAV, Local: "calendar-data"}] = dav.Text(d.calendarData(interviewer))
+	}
+	return properties
+}
+
+// The VCALENDAR of the event, with the VTIMEZONE of its TZID
+func (d davResource) calendarData(interviewer model.Interviewer) string {
+	calendar := ical.NewCalendar(prodId)
+	if start := d.event.Property("DTSTART"); start != nil && start.Params["TZID"] != "" {
+		if location, err := model.LoadLocation(interviewer.TimeZone); err == nil {
+			first, _, _ := start.Time(location)
+			calendar.Components = append(calendar.Components, ical.Timezone(location, first, first))
+		}
+	}
+	calendar.Components = append(calendar.Components, d.event)
+	var data strings.Builder
+	calendar.Encode(&data)
+	return data.String()
+}
+
+func (d davResource) uid() string {
+	return d.event.Property("UID").Value
+}
+
+// The slots and interviews of the interviewer as events, named after their
+// UID unless a client created the slot under another name
+func davResources(s store.Store, interviewer model.Interviewer, now time.Time) ([]davResource, error) {
+	resources := []davResource{}
+	location, err := model.LoadLocation(interviewer.TimeZone)
+	if err != nil {
+		log.Println("Time Zone Error ::", err.Error())
+		return resources, err
+	}
+	slots, err := s.GetSlots(store.InterviewerOwner, interviewer.Id)
+	if err != nil {
+		return resources, err
+	}
+	exceptions, err := s.GetExceptions(store.InterviewerOwner, interviewer.Id)
+	if err != nil {
+		return resources, err
+	}
+	for i := range slots {
+		event, _ := slotEvent(slots[i], location, now)
+		if event == nil {
+			continue
+		}
+		// The exceptions of a recurring slot are its EXDATEs
+		for _, exception := range exceptions {
+			if event.Property("RRULE") != nil && strings.HasPrefix(exception.Uid, slotUid(slots[i])+"/") {
+				event.AddLocalTime("EXDATE", slots[i].InitialTime.On(exception.From, location), location)
+			}
+		}
+		resources = append(resources, newDavResource(event, &slots[i]))
+	}
+	bookings, err := s.GetBookings(store.InterviewerOwner, interviewer.Id)
+	if err != nil {
+		return resources, err
+	}
+	for _, booking := range bookings {
+		event, err := bookingEvent(s, store.InterviewerOwner, booking, now)
+		if err != nil {
+			return resources, err
+		}
+		resources = append(resources, newDavResource(event, nil))
+	}
+	return resources, nil
+}
+
+// The event of the calendar collection named name
+func findDavResource(s store.Store, interviewer model.Interviewer, name string, now time.Time) (davResource, error) {
+	resources, err := davResources(s, interviewer, now)
+	if err != nil {
+		return davResource{}, err
+	}
+	for _, resource := range resources {
+		if resource.name == name {
+			return resource, nil
+		}
+	}
+	return davResource{}, store.ErrNotFound
+}
+
+// The ETag hashes the event but its DTSTAMP, which is always now
+func newDavResource(event *ical.Component, slot *model.Slot) davResource {
+	hash := sha1.New()
+	for _, property := range event.Properties {
+		if property.Name != "DTSTAMP" {
+			hash.Write([]byte(property.String() + "\n"))
+		}
+	}
+	resource := davResource{event: event, slot: slot, etag: `"` + hex.EncodeToString(hash.Sum(nil))[:20] + `"`}
+	resource.name = davName(resource.uid())
+	if slot != nil && slot.Resource != "" {
+		resource.name = slot.Resource
+	}
+	return resource
+}
+
+// Resource names keep the letters, digits and "@._-" of the UIDs, the other
+// bytes being written as "=XX"
+func davName(uid string) string {
+	var name strings.Builder
+	for i := 0; i < len(uid); i++ {
+		c := uid[i]
+		if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("@._-", c) >= 0 {
+			name.WriteByte(c)
+		} else {
+			fmt.Fprintf(&name, "=%02X", c)
+		}
+	}
+	return name.String() + ".ics"
+}
+
+// The slots of the events of a PUT: the recurring or single event first,
+// then its moved occurrences, named after its UID and their RECURRENCE-ID
+func davSlots(calendar *ical.Component, location *time.Location) ([]model.Slot, error) {
+	var master *model.Slot
+	occurrences := []model.Slot{}
+	uid := ""
+	for _, event := range calendar.Components {
+		if event.Name != "VEVENT" {
+			continue
+		}
+		eventUid := imports.Text(event, "UID")
+		if err := imports.CheckUid(eventUid); err != nil {
+			return nil, err
+		}
+		if uid != "" && eventUid != uid {
+			return nil, errors.New("the events of a resource must have the same UID")
+		}
+		uid = eventUid
+		start, end, date, err := imports.EventTimes(event, location)
+		if err != nil {
+			return nil, err
+		}
+		slot, err := imports.EventSlot(start, end, date, location)
+		if err != nil {
+			return nil, err
+		}
+		slot.Uid = uid
+		if recurrence := event.Property("RECURRENCE-ID"); recurrence != nil {
+			id, idDate, err := recurrence.Time(location)
+			if err != nil {
+				return nil, err
+			}
+			slot.Uid += "/" + imports.OccurrenceKey(id, idDate)
+			if err = slot.Validate(); err != nil {
+				return nil, err
+			}
+			occurrences = append(occurrences, slot)
+			continue
+		}
+		if property := event.Property("RRULE"); property != nil {
+			rule, err := rrule.ParseIn(property.Value, start.Location())
+			if err != nil {
+				return nil, err
+			}
+			if !date && model.DateOf(start) != *slot.Date {
+				return nil, errors.New("a recurring event must be on the same day in the interviewer's time zone")
+			}
+			slot.RRule = rule.String()
+		}
+		if err = slot.Validate(); err != nil {
+			return nil, err
+		}
+		master = &slot
+	}
+	if master == nil {
+		return nil, errors.New("expected a VEVENT")
+	}
+	return append([]model.Slot{*master}, occurrences...), nil
+}
+
+// The occurrences of the recurring event excluded from the slot, by EXDATE
+// or by RECURRENCE-ID, as exceptions named after the UID of the slot
+func davExcluded(calendar *ical.Component, location *time.Location, slot model.Slot) []model.Exception {
+	exceptions := []model.Exception{}
+	if slot.RRule == "" && len(slot.Weekdays) == 0 {
+		return exceptions
+	}
+	for _, event := range calendar.Components {
+		excluded := []ical.Property{}
+		if event.Name == "VEVENT" && event.Property("RECURRENCE-ID") != nil {
+			excluded = append(excluded, *event.Property("RECURRENCE-ID"))
+		} else if event.Name == "VEVENT" {
+			excluded = event.All("EXDATE")
+		}
+		for _, property := range excluded {
+			times, date, err := property.Times(location)
+			if err != nil {
+				continue
+			}
+			for _, t := range times {
+				day := model.DateOf(t.In(location))
+				if date {
+					day = model.DateOf(t)
+				}
+				exceptions = append(exceptions, model.Exception{
+					PersonId:    slot.PersonId,
+					From:        day,
+					InitialTime: slot.InitialTime,
+					To:          day,
+					FinalTime:   slot.FinalTime,
+					Reason:      imports.Truncate(imports.Text(event, "SUMMARY")),
+					Uid:         slotUid(slot) + "/" + imports.OccurrenceKey(t, date),
+				})
+			}
+		}
+	}
+	return exceptions
+}
+
+// A weekly slot stays one when the client keeps its plain weekly RRULE
+func keepWeekdays(slot *model.Slot, current model.Slot) {
+	if len(current.Weekdays) == 0 || slot.RRule == "" {
+		return
+	}
+	rule, err := rrule.Parse(slot.RRule)
+	if err != nil || rule.Freq != rrule.Weekly || rule.Interval != 1 || rule.Count != 0 || !rule.Until.IsZero() || len(rule.ByDay) == 0 || len(rule.ByMonth) > 0 || len(rule.ByMonthDay) > 0 {
+		return
+	}
+	weekdays := []time.Weekday{}
+	for _, day := range rule.ByDay {
+		if day.Ordinal != 0 {
+			return
+		}
+		weekdays = append(weekdays, day.Weekday)
+	}
+	slot.Date, slot.RRule, slot.Weekdays = nil, "", weekdays
+}
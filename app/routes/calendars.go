@@ -0,0 +1,260 @@
+package routes
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
+)
+
+// Calendars work the same for candidates and interviewers, the owner
+// telling which of the :candidate_id or :interviewer_id parameters is used
+
+const prodId = "-//KI Labs//Interview Calendar API//EN"
+
+// ResetCalendarToken creates the secret of the person's calendar feed,
+// replacing the previous one, and answers with the feed URL
+func ResetCalendarToken(tokens store.CalendarTokenStore, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	personId, _ := strconv.Atoi(ps.ByName(owner.Column()))
+	token, ok := newSecret(w)
+	if !ok {
+		return
+	}
+	if err := tokens.SetCalendarToken(owner, personId, token); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"Token": token,
+		"URL":   fmt.Sprintf("%s/%s/%d/calendar.ics?token=%s", baseURL(r), owner, personId, token),
+	})
+}
+
+// A random secret of 64 hexadecimal digits, answering with a 500 when there
+// is none
+func newSecret(w http.ResponseWriter) (string, bool) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Println("Calendar Token Error ::", err.Error())
+		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return "", false
+	}
+	return hex.EncodeToString(secret), true
+}
+
+// The scheme and host the request was sent to
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// GetCalendar answers with the iCalendar feed of the person's slots and
+// interviews. A wrong token is answered like an unknown person.
+func GetCalendar(s store.Store, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	personId, _ := strconv.Atoi(ps.ByName(owner.Column()))
+	token, err := s.GetCalendarToken(owner, personId)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	given := r.URL.Query().Get("token")
+	if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+
+	calendar, err := personCalendar(s, owner, personId)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", fmt.Sprintf("%s-%d.ics", owner, personId)))
+	w.WriteHeader(http.StatusOK)
+	if err = calendar.Encode(w); err != nil {
+		log.Println("Calendar Write Error ::", err.Error())
+	}
+}
+
+// The slots of the person as transparent VEVENTs in their time zone, and
+// their interviews in UTC
+func personCalendar(s store.Store, owner store.Owner, personId int) (*ical.Component, error) {
+	name, timeZone, err := person(s, owner, personId)
+	if err != nil {
+		return nil, err
+	}
+	location, err := model.LoadLocation(timeZone)
+	if err != nil {
+		log.Println("Time Zone Error ::", err.Error())
+		return nil, err
+	}
+	now := time.Now()
+	calendar := ical.NewCalendar(prodId)
+	calendar.Add("METHOD", "PUBLISH")
+	calendar.AddText("X-WR-CALNAME", name+" - KI Labs interviews")
+	calendar.Add("X-PUBLISHED-TTL", "PT1H")
+
+	slots, err := s.GetSlots(owner, personId)
+	if err != nil {
+		return nil, err
+	}
+	// The VTIMEZONE covers the slots, from the earliest to the latest one,
+	// its yearly rules the recurring ones after it
+	earliest, latest := now, now
+	for _, slot := range slots {
+		event, start := slotEvent(slot, location, now)
+		if event == nil {
+			continue
+		}
+		if start.Before(earliest) {
+			earliest = start
+		}
+		if start.After(latest) {
+			latest = start
+		}
+		calendar.Components = append(calendar.Components, event)
+	}
+	if location != time.UTC {
+		timezone := ical.Timezone(location, earliest, latest)
+		calendar.Components = append([]*ical.Component{timezone}, calendar.Components...)
+	}
+
+	bookings, err := s.GetBookings(owner, personId)
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		event, err := bookingEvent(s, owner, booking, now)
+		if err != nil {
+			return nil, err
+		}
+		calendar.Components = append(calendar.Components, event)
+	}
+	return calendar, nil
+}
+
+// Weekly slots start in the week of this Monday, so that their events don't
+// change from one week to the next
+var weeklyEpoch = model.Date{Year: 2024, Month: time.January, Day: 1}
+
+// A slot as a VEVENT with its start, nil for weekly slots without weekdays
+func slotEvent(slot model.Slot, location *time.Location, now time.Time) (*ical.Component, time.Time) {
+	var date model.Date
+	var rule string
+	switch {
+	case slot.RRule != "":
+		parsed, err := slot.Rule(location)
+		if err != nil {
+			log.Println("RRule Error ::", err.Error())
+			return nil, time.Time{}
+		}
+		date, rule = *slot.Date, parsed.Format(location)
+	case slot.Date != nil:
+		date = *slot.Date
+	case len(slot.Weekdays) > 0:
+		date = weeklyEpoch.AddDays(7)
+		days := []string{}
+		for _, weekday := range slot.Weekdays {
+			if first := weeklyEpoch.AddDays((int(weekday) + 6) % 7); first.Before(date) {
+				date = first
+			}
+			days = append(days, strings.ToUpper(weekday.String()[:2]))
+		}
+		rule = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	default:
+		return nil, time.Time{}
+	}
+
+	start := slot.InitialTime.On(date, location)
+	event := &ical.Component{Name: "VEVENT"}
+	event.Add("UID", slotUid(slot))
+	event.AddTime("DTSTAMP", now)
+	event.AddLocalTime("DTSTART", start, location)
+	event.AddLocalTime("DTEND", slot.FinalTime.On(date, location), location)
+	if rule != "" {
+		event.Add("RRULE", rule)
+	}
+	event.AddText("SUMMARY", "Available for interviews")
+	event.Add("TRANSP", "TRANSPARENT")
+	return event, start
+}
+
+// The UID of the iCalendar entry the slot was imported from, or one made of
+// its id
+func slotUid(slot model.Slot) string {
+	if slot.Uid != "" {
+		return slot.Uid
+	}
+	return fmt.Sprintf("slot-%d@kilabs-api", slot.Id)
+}
+
+// Interview statuses as VEVENT statuses
+var eventStatuses = map[string]string{
+	model.BookingProposed:  "TENTATIVE",
+	model.BookingCancelled: "CANCELLED",
+}
+
+// A booking as a VEVENT named after the other people of the interview. Its
+// SEQUENCE counts the changes, so that calendars update their copy.
+func bookingEvent(s store.Store, owner store.Owner, booking model.Booking, now time.Time) (*ical.Component, error) {
+	others := []string{}
+	if owner == store.InterviewerOwner {
+		name, _, err := person(s, store.CandidateOwner, booking.Candidate.Id)
+		if err != nil {
+			return nil, err
+		}
+		others = append(others, name)
+	} else {
+		for _, interviewer := range booking.Interviewers {
+			name, _, err := person(s, store.InterviewerOwner, interviewer.Id)
+			if err != nil {
+				return nil, err
+			}
+			others = append(others, name)
+		}
+	}
+	history, err := s.GetBookingHistory(booking.Id)
+	if err != nil {
+		return nil, err
+	}
+	status, ok := eventStatuses[booking.Status]
+	if !ok {
+		status = "CONFIRMED"
+	}
+
+	event := &ical.Component{Name: "VEVENT"}
+	event.Add("UID", fmt.Sprintf("interview-%d@kilabs-api", booking.Id))
+	event.AddTime("DTSTAMP", now)
+	event.AddTime("DTSTART", booking.Start)
+	event.AddTime("DTEND", booking.End)
+	event.AddText("SUMMARY", "Interview with "+strings.Join(others, ", "))
+	event.Add("STATUS", status)
+	if len(history) > 0 {
+		event.Add("SEQUENCE", strconv.Itoa(len(history)-1))
+		event.AddTime("LAST-MODIFIED", history[len(history)-1].At)
+	}
+	return event, nil
+}
+
+// The name and time zone of a candidate or an interviewer
+func person(s store.Store, owner store.Owner, id int) (string, string, error) {
+	if owner == store.CandidateOwner {
+		candidate, err := s.GetCandidate(id)
+		return candidate.Name, candidate.TimeZone, err
+	}
+	interviewer, err := s.GetInterviewer(id)
+	return interviewer.Name, interviewer.TimeZone, err
+}
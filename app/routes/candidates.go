@@ -1,18 +1,17 @@
 package routes
 
 import (
-	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
 )
 
-func AddCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func AddCandidate(candidates store.CandidateStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	candidate := model.Candidate{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&candidate); err != nil {
@@ -21,68 +20,49 @@ func AddCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprou
 		return
 	}
 	defer r.Body.Close()
-
-	query := "INSERT INTO candidates VALUES (NULL, ?, NOW());"
-	result, err := db.Exec(query, candidate.Name)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
+	if candidate.TimeZone == "" {
+		candidate.TimeZone = model.DefaultTimeZone
 	}
-	candidateId, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Database Last Insert Id Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	if _, err := model.LoadLocation(candidate.TimeZone); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	candidate.Id = int(candidateId)
 
+	if err := candidates.AddCandidate(&candidate); err != nil {
+		writeStoreError(w, err)
+		return
+	}
 	writeJSON(w, http.StatusOK, candidate)
 	return
 }
 
-func GetAllCandidates(db *sql.DB, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	candidates := []model.Candidate{}
-	query := "SELECT id, name FROM candidates;"
-	rows, err := db.Query(query)
+func GetAllCandidates(candidates store.CandidateStore, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	all, err := candidates.GetAllCandidates()
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
-	defer rows.Close()
-	for rows.Next() {
-		candidate := model.Candidate{}
-		err = rows.Scan(&candidate.Id, &candidate.Name)
-		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-		candidates = append(candidates, candidate)
-	}
-	if len(candidates) == 0 {
+	if len(all) == 0 {
 		writeError(w, http.StatusNoContent, "No Content")
 		return
 	}
-	writeJSON(w, http.StatusOK, candidates)
+	writeJSON(w, http.StatusOK, all)
 	return
 }
 
-func GetCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	candidate := model.Candidate{}
-	query := "SELECT id, name FROM candidates WHERE id = ?;"
-	err := db.QueryRow(query, ps.ByName("candidate_id")).Scan(&candidate.Id, &candidate.Name)
+func GetCandidate(candidates store.CandidateStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	candidateId, _ := strconv.Atoi(ps.ByName("candidate_id"))
+	candidate, err := candidates.GetCandidate(candidateId)
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, candidate)
 	return
 }
 
-func UpdateCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func UpdateCandidate(candidates store.CandidateStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	candidate := model.Candidate{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&candidate); err != nil {
@@ -93,178 +73,104 @@ func UpdateCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps http
 	defer r.Body.Close()
 
 	candidate.Id, _ = strconv.Atoi(ps.ByName("candidate_id"))
-	query := "UPDATE candidates SET name = ? WHERE id = ?;"
-	_, err := db.Exec(query, candidate.Name, candidate.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	if candidate.TimeZone == "" {
+		current, err := candidates.GetCandidate(candidate.Id)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		candidate.TimeZone = current.TimeZone
+	}
+	if _, err := model.LoadLocation(candidate.TimeZone); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := candidates.UpdateCandidate(candidate); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, candidate)
 	return
 }
 
-func DeleteCandidate(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	query := "DELETE FROM candidates WHERE id = ?;"
-	_, err := db.Exec(query, ps.ByName("candidate_id"))
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+func DeleteCandidate(candidates store.CandidateStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	candidateId, _ := strconv.Atoi(ps.ByName("candidate_id"))
+	if err := candidates.DeleteCandidate(candidateId); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, nil)
 	return
 }
 
-func AddCandidateSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func AddCandidateSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	slot := model.Slot{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&slot); err != nil {
-		log.Println("Bad Request")
-		writeError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	defer r.Body.Close()
-
-	slot.PersonId, _ = strconv.Atoi(ps.ByName("candidate_id"))
-
-	query := "INSERT INTO slots SET candidate_id = ?, initial_time = ?, final_time = ?"
-	result, err := db.Exec(query, slot.PersonId, slot.InitialTime, slot.FinalTime)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
-	}
-	slotId, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Database Last Insert Id Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.SetDefaults()
+	if err := slot.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	for _, weekday := range slot.Weekdays {
-		query = "INSERT INTO slots_weekdays SET slot_id = ?, weekday = ?;"
-		_, err = db.Exec(query, slotId, weekday)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
+	slot.PersonId, _ = strconv.Atoi(ps.ByName("candidate_id"))
+	if err := slots.AddSlot(store.CandidateOwner, &slot); err != nil {
+		writeStoreError(w, err)
+		return
 	}
-	slot.Id = int(slotId)
 	writeJSON(w, http.StatusOK, slot)
 	return
 }
 
-func GetCandidateSlots(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	slots := []model.Slot{}
-	candidateId := ps.ByName("candidate_id")
-	query := "SELECT id, initial_time, final_time FROM slots WHERE candidate_id = ?"
-	rows, err := db.Query(query, candidateId)
+func GetCandidateSlots(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	candidateId, _ := strconv.Atoi(ps.ByName("candidate_id"))
+	candidateSlots, err := slots.GetSlots(store.CandidateOwner, candidateId)
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		slot := model.Slot{}
-		slot.PersonId, _ = strconv.Atoi(candidateId)
-		err = rows.Scan(&slot.Id, &slot.InitialTime, &slot.FinalTime)
-		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-
-		weekdays := []time.Weekday{}
-		query = "SELECT weekday FROM slots_weekdays WHERE slot_id = ?"
-		rowsWeekdays, err := db.Query(query, slot.Id)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-		defer rowsWeekdays.Close()
-		for rowsWeekdays.Next() {
-			var weekday time.Weekday
-			err = rowsWeekdays.Scan(&weekday)
-			if err != nil {
-				log.Println("Database Scan Error ::", err.Error())
-				writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-				return
-			}
-			weekdays = append(weekdays, weekday)
-		}
-		slot.Weekdays = weekdays
-		slots = append(slots, slot)
-	}
-
-	writeJSON(w, http.StatusOK, slots)
+	writeJSON(w, http.StatusOK, candidateSlots)
 }
 
-func UpdateCandidateSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func UpdateCandidateSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	slot := model.Slot{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&slot); err != nil {
-		log.Println("Bad Request")
-		writeError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	defer r.Body.Close()
-
-	slot.Id, _ = strconv.Atoi(ps.ByName("slot_id"))
-	slot.PersonId, _ = strconv.Atoi(ps.ByName("candidate_id"))
-
-	query := "UPDATE slots SET initial_time = ?, final_time = ? WHERE id = ?"
-	_, err := db.Exec(query, slot.InitialTime, slot.FinalTime, slot.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.SetDefaults()
+	if err := slot.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	query = "DELETE FROM slots_weekdays WHERE slot_id = ?"
-	_, err = db.Exec(query, slot.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.Id, _ = strconv.Atoi(ps.ByName("slot_id"))
+	slot.PersonId, _ = strconv.Atoi(ps.ByName("candidate_id"))
+	if err := slots.UpdateSlot(slot); err != nil {
+		writeStoreError(w, err)
 		return
 	}
-
-	for _, weekday := range slot.Weekdays {
-		query = "INSERT INTO slots_weekdays SET slot_id = ?, weekday = ?;"
-		_, err = db.Exec(query, slot.Id, weekday)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-	}
-
 	writeJSON(w, http.StatusOK, slot)
 	return
 }
 
-func DeleteCandidateSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	slotId := ps.ByName("slot_id")
-	query := "DELETE FROM slots_weekdays WHERE slot_id = ?"
-	_, err := db.Exec(query, slotId)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
-	}
-
-	query = "DELETE FROM slots WHERE id = ?"
-	_, err = db.Exec(query, slotId)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+func DeleteCandidateSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	slotId, _ := strconv.Atoi(ps.ByName("slot_id"))
+	if err := slots.DeleteSlot(slotId); err != nil {
+		writeStoreError(w, err)
 		return
 	}
-
 	writeJSON(w, http.StatusOK, nil)
 	return
 }
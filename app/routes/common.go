@@ -3,6 +3,8 @@ package routes
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/paulofeitor/kilabs-api/app/store"
 )
 
 func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
@@ -20,3 +22,12 @@ func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
 func writeError(w http.ResponseWriter, code int, message string) {
 	writeJSON(w, code, map[string]string{"error": message})
 }
+
+// Errors coming from the stores are already logged by them
+func writeStoreError(w http.ResponseWriter, err error) {
+	if err == store.ErrNotFound {
+		writeError(w, http.StatusNotFound, http.StatusText(http.StatusNotFound))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+}
@@ -0,0 +1,81 @@
+package routes
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
+)
+
+// Exceptions work the same for candidates and interviewers, the owner
+// telling which of the :candidate_id or :interviewer_id parameters is used
+
+func AddException(exceptions store.ExceptionStore, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	exception, ok := decodeException(w, r)
+	if !ok {
+		return
+	}
+	exception.PersonId, _ = strconv.Atoi(ps.ByName(owner.Column()))
+	if err := exceptions.AddException(owner, &exception); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exception)
+}
+
+func GetExceptions(exceptions store.ExceptionStore, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	personId, _ := strconv.Atoi(ps.ByName(owner.Column()))
+	personExceptions, err := exceptions.GetExceptions(owner, personId)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, personExceptions)
+}
+
+func UpdateException(exceptions store.ExceptionStore, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	exception, ok := decodeException(w, r)
+	if !ok {
+		return
+	}
+	exception.Id, _ = strconv.Atoi(ps.ByName("exception_id"))
+	exception.PersonId, _ = strconv.Atoi(ps.ByName(owner.Column()))
+	if err := exceptions.UpdateException(exception); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, exception)
+}
+
+func DeleteException(exceptions store.ExceptionStore, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	exceptionId, _ := strconv.Atoi(ps.ByName("exception_id"))
+	if err := exceptions.DeleteException(exceptionId); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// Reads and validates the exception of the request body, answering with a
+// 400 when it is not valid
+func decodeException(w http.ResponseWriter, r *http.Request) (model.Exception, bool) {
+	exception := model.Exception{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&exception); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return exception, false
+	}
+	defer r.Body.Close()
+	exception.SetDefaults()
+	if err := exception.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return exception, false
+	}
+	return exception, true
+}
@@ -0,0 +1,189 @@
+package routes
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/scheduling"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+// GetFreeBusy answers with the free, busy and unavailable time of the
+// people asked for, as in
+// ?interviewers=7,8&candidates=1&from=2026-11-02&to=2026-11-06&timezone=Europe/Berlin.
+// The dates default like the slot matching ones. The answer is JSON, or a
+// VCALENDAR of VFREEBUSY components with ?format=ics or an Accept of
+// text/calendar.
+func GetFreeBusy(s store.Store, matching *config.MatchingConfig, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	query := r.URL.Query()
+	response, candidateIds, interviewerIds, location, err := freeBusyRequest(query, matching)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	period := scheduling.Period{Start: response.From.In(location), End: response.To.AddDays(1).In(location)}
+	now := time.Now()
+
+	for _, id := range candidateIds {
+		candidate, err := s.GetCandidate(id)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		personAvailability, err := availability(s, store.CandidateOwner, candidate.Id, candidate.TimeZone, true)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		response.Candidates = append(response.Candidates, personFreeBusy(candidate.Id, candidate.Name, personAvailability, period, location))
+	}
+	for _, id := range interviewerIds {
+		interviewer, err := s.GetInterviewer(id)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		personAvailability, err := availability(s, store.InterviewerOwner, interviewer.Id, interviewer.TimeZone, true)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		// Unlike in the slot matching, every hold keeps the interviewer busy
+		held, err := heldPeriods(s, interviewer.Id, 0, now)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		personAvailability.Busy = append(personAvailability.Busy, held...)
+		response.Interviewers = append(response.Interviewers, personFreeBusy(interviewer.Id, interviewer.Name, personAvailability, period, location))
+	}
+
+	if query.Get("format") != "ics" && !strings.Contains(r.Header.Get("Accept"), "text/calendar") {
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+	calendar := ical.NewCalendar(prodId)
+	calendar.Add("METHOD", "PUBLISH")
+	for _, person := range response.Candidates {
+		calendar.Components = append(calendar.Components, freeBusyComponent(store.CandidateOwner, person, period, now))
+	}
+	for _, person := range response.Interviewers {
+		calendar.Components = append(calendar.Components, freeBusyComponent(store.InterviewerOwner, person, period, now))
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	if err = calendar.Encode(w); err != nil {
+		log.Println("Calendar Write Error ::", err.Error())
+	}
+}
+
+// Reads the ids, dates and time zone of the query into a response without
+// people yet
+func freeBusyRequest(query url.Values, matching *config.MatchingConfig) (model.FreeBusyResponse, []int, []int, *time.Location, error) {
+	response := model.FreeBusyResponse{Candidates: []model.FreeBusy{}, Interviewers: []model.FreeBusy{}}
+	candidateIds, err := queryIds("candidates", query["candidates"])
+	if err != nil {
+		return response, nil, nil, nil, err
+	}
+	interviewerIds, err := queryIds("interviewers", query["interviewers"])
+	if err != nil {
+		return response, nil, nil, nil, err
+	}
+	if len(candidateIds)+len(interviewerIds) == 0 {
+		return response, nil, nil, nil, errors.New("ask for candidates, interviewers or both, as in ?interviewers=7,8")
+	}
+	location, err := model.LoadLocation(query.Get("timezone"))
+	if err != nil {
+		return response, nil, nil, nil, err
+	}
+	var from, to *model.Date
+	for _, date := range []struct {
+		name  string
+		value **model.Date
+	}{{"from", &from}, {"to", &to}} {
+		if value := query.Get(date.name); value != "" {
+			parsed, err := model.ParseDate(value)
+			if err != nil {
+				return response, nil, nil, nil, err
+			}
+			*date.value = &parsed
+		}
+	}
+	if response.From, response.To, err = dateRange(from, to, matching, location); err != nil {
+		return response, nil, nil, nil, err
+	}
+	response.TimeZone = location.String()
+	return response, candidateIds, interviewerIds, location, nil
+}
+
+// Comma separated ids, the parameter being possibly repeated
+func queryIds(name string, values []string) ([]int, error) {
+	ids := []int{}
+	for _, value := range values {
+		for _, field := range strings.Split(value, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || id <= 0 {
+				return nil, fmt.Errorf("invalid %s id %q", name, field)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func personFreeBusy(id int, name string, availability scheduling.Availability, period scheduling.Period, location *time.Location) model.FreeBusy {
+	freeBusy := availability.FreeBusy(period)
+	return model.FreeBusy{
+		Id:          id,
+		Name:        name,
+		Free:        modelPeriods(freeBusy.Free, location),
+		Busy:        modelPeriods(freeBusy.Busy, location),
+		Unavailable: modelPeriods(freeBusy.Unavailable, location),
+	}
+}
+
+func modelPeriods(periods []scheduling.Period, location *time.Location) []model.Period {
+	converted := []model.Period{}
+	for _, period := range periods {
+		converted = append(converted, model.Period{Start: period.Start.In(location), End: period.End.In(location)})
+	}
+	return converted
+}
+
+// A VFREEBUSY of the person, from RFC 5545 3.6.4, naming them with an
+// ATTENDEE
+func freeBusyComponent(owner store.Owner, person model.FreeBusy, period scheduling.Period, now time.Time) *ical.Component {
+	component := &ical.Component{Name: "VFREEBUSY"}
+	component.Add("UID", fmt.Sprintf("freebusy-%s-%d@kilabs-api", owner, person.Id))
+	component.AddTime("DTSTAMP", now)
+	component.AddTime("DTSTART", period.Start)
+	component.AddTime("DTEND", period.End)
+	component.Properties = append(component.Properties, ical.Property{
+		Name:   "ATTENDEE",
+		Params: map[string]string{"CN": person.Name},
+		Value:  fmt.Sprintf("urn:kilabs-api:%s:%d", owner, person.Id),
+	})
+	component.AddFreeBusy("FREE", icalPeriods(person.Free))
+	component.AddFreeBusy("BUSY", icalPeriods(person.Busy))
+	component.AddFreeBusy("BUSY-UNAVAILABLE", icalPeriods(person.Unavailable))
+	return component
+}
+
+func icalPeriods(periods []model.Period) []ical.Period {
+	converted := []ical.Period{}
+	for _, period := range periods {
+		converted = append(converted, ical.Period{Start: period.Start, End: period.End})
+	}
+	return converted
+}
@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/scheduling"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+// AddHold reserves the time of the interviewers for the candidate, for the
+// TTL of the request or the configured one
+func AddHold(s store.Store, holds *config.HoldsConfig, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	hold := model.Hold{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&hold); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
+	hold.Start = hold.Start.UTC().Truncate(time.Second)
+	hold.End = hold.End.UTC().Truncate(time.Second)
+	ttl, err := holdTTL(hold.TTL, holds)
+	if err == nil {
+		err = hold.Validate()
+	}
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hold.TTL = ""
+	hold.ExpiresAt = time.Now().UTC().Add(ttl).Truncate(time.Second)
+
+	// The store checks the bookings and the other holds again while it
+	// locks the people, so that two overlapping holds can't both be added
+	candidateAvailability, interviewersAvailability, err := participants(s, hold.Candidate, hold.Interviewers, true)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	period := scheduling.Period{Start: hold.Start, End: hold.End}
+	if !scheduling.Available(period, candidateAvailability, interviewersAvailability) {
+		writeError(w, http.StatusUnprocessableEntity, "the candidate and the interviewers are not all available at that time")
+		return
+	}
+
+	if err = s.AddHold(&hold); err != nil {
+		var conflict *store.ConflictError
+		var holdConflict *store.HoldConflictError
+		switch {
+		case errors.As(err, &conflict):
+			writeConflict(w, conflict)
+		case errors.As(err, &holdConflict):
+			writeJSON(w, http.StatusConflict, map[string]interface{}{
+				"error": fmt.Sprintf("the hold overlaps hold %d", holdConflict.Hold.Id),
+				"hold":  holdConflict.Hold,
+			})
+		default:
+			writeStoreError(w, err)
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, hold)
+}
+
+// Expired holds are not found, even before they are deleted
+func GetHold(holds store.HoldStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, _ := strconv.Atoi(ps.ByName("hold_id"))
+	hold, err := holds.GetHold(id)
+	if err == nil && hold.Expired(time.Now()) {
+		err = store.ErrNotFound
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, hold)
+}
+
+func GetAllHolds(holds store.HoldStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	allHolds, err := holds.GetAllHolds()
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, activeHolds(allHolds, time.Now()))
+}
+
+// ReleaseHold gives the time of the hold back before it expires
+func ReleaseHold(holds store.HoldStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, _ := strconv.Atoi(ps.ByName("hold_id"))
+	if err := holds.DeleteHold(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, nil)
+}
+
+// The TTL of a hold request, the configured one when it has none
+func holdTTL(value string, holds *config.HoldsConfig) (time.Duration, error) {
+	if value == "" {
+		return holds.TTL, nil
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl <= 0 || ttl > holds.MaxTTL {
+		return 0, fmt.Errorf("TTL %q must be a positive duration such as \"15m\", up to %s", value, holds.MaxTTL)
+	}
+	return ttl, nil
+}
+
+// The periods of the active holds of the interviewer, as busy periods, but
+// for the holds of the candidate with candidateId, 0 keeping every hold
+func heldPeriods(holds store.HoldStore, interviewerId, candidateId int, now time.Time) ([]scheduling.Period, error) {
+	periods := []scheduling.Period{}
+	interviewerHolds, err := holds.GetHolds(interviewerId)
+	if err != nil {
+		return periods, err
+	}
+	for _, hold := range activeHolds(interviewerHolds, now) {
+		if hold.Candidate.Id != candidateId {
+			periods = append(periods, scheduling.Period{Start: hold.Start, End: hold.End})
+		}
+	}
+	return periods, nil
+}
+
+func activeHolds(holds []model.Hold, now time.Time) []model.Hold {
+	active := []model.Hold{}
+	for _, hold := range holds {
+		if !hold.Expired(now) {
+			active = append(active, hold)
+		}
+	}
+	return active
+}
@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/ical"
+	"github.com/paulofeitor/kilabs-api/app/imports"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+// Imports work the same for candidates and interviewers, the owner telling
+// which of the :candidate_id or :interviewer_id parameters is used
+
+// Largest iCalendar file imported
+const maxImportBytes = 1 << 20
+
+// ImportCalendar turns the iCalendar file of the request body into slots
+// and exceptions of the person, and answers with an import report. Free
+// VEVENTs become slots, busy VEVENTs and VFREEBUSY periods exceptions. The
+// entries already imported, known by their UID, are skipped, and the others
+// created in a single transaction. The recurring busy events are expanded
+// over the matching horizon, as far as the matching looks by default.
+func ImportCalendar(s store.Store, matching *config.MatchingConfig, owner store.Owner, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	personId, _ := strconv.Atoi(ps.ByName(owner.Column()))
+	_, timeZone, err := person(s, owner, personId)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	location, err := model.LoadLocation(timeZone)
+	if err != nil {
+		log.Println("Time Zone Error ::", err.Error())
+		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		return
+	}
+
+	calendar, err := ical.Decode(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	defer r.Body.Close()
+	if err == nil && calendar.Name != "VCALENDAR" {
+		err = fmt.Errorf("expected a VCALENDAR, not a %s", calendar.Name)
+	}
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	calendarImport := imports.New(location, time.Now(), matching.HorizonDays)
+	if err = findImported(s, owner, personId, calendarImport.Imported); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	calendarImport.Read(calendar)
+
+	// Every entry is created, or none is
+	write := store.CalendarWrite{Owner: owner, PersonId: personId}
+	for _, entry := range calendarImport.Pending {
+		if entry.Slot != nil {
+			write.Slots = append(write.Slots, entry.Slot)
+		} else {
+			write.Exceptions = append(write.Exceptions, entry.Exception)
+		}
+	}
+	if err = s.WriteCalendar(write); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	calendarImport.Report.Created = calendarImport.Pending
+	writeJSON(w, http.StatusOK, calendarImport.Report)
+}
+
+// Adds the UIDs of the slots and exceptions already imported to imported
+func findImported(s store.Store, owner store.Owner, personId int, imported map[string]bool) error {
+	slots, err := s.GetSlots(owner, personId)
+	if err != nil {
+		return err
+	}
+	for _, slot := range slots {
+		imported[slot.Uid] = true
+	}
+	exceptions, err := s.GetExceptions(owner, personId)
+	if err != nil {
+		return err
+	}
+	for _, exception := range exceptions {
+		imported[exception.Uid] = true
+	}
+	return nil
+}
@@ -1,18 +1,17 @@
 package routes
 
 import (
-	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
 	"strconv"
-	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
 )
 
-func AddInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func AddInterviewer(interviewers store.InterviewerStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	interviewer := model.Interviewer{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&interviewer); err != nil {
@@ -21,68 +20,49 @@ func AddInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httpr
 		return
 	}
 	defer r.Body.Close()
-
-	query := "INSERT INTO interviewers VALUES (NULL, ?, NOW());"
-	result, err := db.Exec(query, interviewer.Name)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
+	if interviewer.TimeZone == "" {
+		interviewer.TimeZone = model.DefaultTimeZone
 	}
-	interviewerId, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Database Last Insert Id Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	if _, err := model.LoadLocation(interviewer.TimeZone); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	interviewer.Id = int(interviewerId)
 
+	if err := interviewers.AddInterviewer(&interviewer); err != nil {
+		writeStoreError(w, err)
+		return
+	}
 	writeJSON(w, http.StatusOK, interviewer)
 	return
 }
 
-func GetAllInterviewers(db *sql.DB, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	interviewers := []model.Interviewer{}
-	query := "SELECT id, name FROM interviewers;"
-	rows, err := db.Query(query)
+func GetAllInterviewers(interviewers store.InterviewerStore, w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	all, err := interviewers.GetAllInterviewers()
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
-	defer rows.Close()
-	for rows.Next() {
-		interviewer := model.Interviewer{}
-		err = rows.Scan(&interviewer.Id, &interviewer.Name)
-		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-		interviewers = append(interviewers, interviewer)
-	}
-	if len(interviewers) == 0 {
+	if len(all) == 0 {
 		writeError(w, http.StatusNoContent, "No Content")
 		return
 	}
-	writeJSON(w, http.StatusOK, interviewers)
+	writeJSON(w, http.StatusOK, all)
 	return
 }
 
-func GetInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	interviewer := model.Interviewer{}
-	query := "SELECT id, name FROM interviewers WHERE id = ?;"
-	err := db.QueryRow(query, ps.ByName("interviewer_id")).Scan(&interviewer.Id, &interviewer.Name)
+func GetInterviewer(interviewers store.InterviewerStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewerId, _ := strconv.Atoi(ps.ByName("interviewer_id"))
+	interviewer, err := interviewers.GetInterviewer(interviewerId)
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, interviewer)
 	return
 }
 
-func UpdateInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func UpdateInterviewer(interviewers store.InterviewerStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	interviewer := model.Interviewer{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&interviewer); err != nil {
@@ -93,178 +73,104 @@ func UpdateInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps ht
 	defer r.Body.Close()
 
 	interviewer.Id, _ = strconv.Atoi(ps.ByName("interviewer_id"))
-	query := "UPDATE interviewers SET name = ? WHERE id = ?;"
-	_, err := db.Exec(query, interviewer.Name, interviewer.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	if interviewer.TimeZone == "" {
+		current, err := interviewers.GetInterviewer(interviewer.Id)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		interviewer.TimeZone = current.TimeZone
+	}
+	if _, err := model.LoadLocation(interviewer.TimeZone); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := interviewers.UpdateInterviewer(interviewer); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, interviewer)
 	return
 }
 
-func DeleteInterviewer(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	query := "DELETE FROM interviewers WHERE id = ?;"
-	_, err := db.Exec(query, ps.ByName("interviewer_id"))
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+func DeleteInterviewer(interviewers store.InterviewerStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewerId, _ := strconv.Atoi(ps.ByName("interviewer_id"))
+	if err := interviewers.DeleteInterviewer(interviewerId); err != nil {
+		writeStoreError(w, err)
 		return
 	}
 	writeJSON(w, http.StatusOK, nil)
 	return
 }
 
-func AddInterviewerSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func AddInterviewerSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	slot := model.Slot{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&slot); err != nil {
-		log.Println("Bad Request")
-		writeError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	defer r.Body.Close()
-
-	slot.PersonId, _ = strconv.Atoi(ps.ByName("interviewer_id"))
-
-	query := "INSERT INTO slots SET interviewer_id = ?, initial_time = ?, final_time = ?"
-	result, err := db.Exec(query, slot.PersonId, slot.InitialTime, slot.FinalTime)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
-	}
-	slotId, err := result.LastInsertId()
-	if err != nil {
-		log.Println("Database Last Insert Id Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.SetDefaults()
+	if err := slot.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	for _, weekday := range slot.Weekdays {
-		query = "INSERT INTO slots_weekdays SET slot_id = ?, weekday = ?;"
-		_, err = db.Exec(query, slotId, weekday)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
+	slot.PersonId, _ = strconv.Atoi(ps.ByName("interviewer_id"))
+	if err := slots.AddSlot(store.InterviewerOwner, &slot); err != nil {
+		writeStoreError(w, err)
+		return
 	}
-	slot.Id = int(slotId)
 	writeJSON(w, http.StatusOK, slot)
 	return
 }
 
-func GetInterviewerSlots(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	slots := []model.Slot{}
-	interviewerId := ps.ByName("interviewer_id")
-	query := "SELECT id, initial_time, final_time FROM slots WHERE interviewer_id = ?"
-	rows, err := db.Query(query, interviewerId)
+func GetInterviewerSlots(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	interviewerId, _ := strconv.Atoi(ps.ByName("interviewer_id"))
+	interviewerSlots, err := slots.GetSlots(store.InterviewerOwner, interviewerId)
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		writeStoreError(w, err)
 		return
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		slot := model.Slot{}
-		slot.PersonId, _ = strconv.Atoi(interviewerId)
-		err = rows.Scan(&slot.Id, &slot.InitialTime, &slot.FinalTime)
-		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-
-		weekdays := []time.Weekday{}
-		query = "SELECT weekday FROM slots_weekdays WHERE slot_id = ?"
-		rowsWeekdays, err := db.Query(query, slot.Id)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-		defer rowsWeekdays.Close()
-		for rowsWeekdays.Next() {
-			var weekday time.Weekday
-			err = rowsWeekdays.Scan(&weekday)
-			if err != nil {
-				log.Println("Database Scan Error ::", err.Error())
-				writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-				return
-			}
-			weekdays = append(weekdays, weekday)
-		}
-		slot.Weekdays = weekdays
-		slots = append(slots, slot)
-	}
-
-	writeJSON(w, http.StatusOK, slots)
+	writeJSON(w, http.StatusOK, interviewerSlots)
 }
 
-func UpdateInterviewerSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func UpdateInterviewerSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	slot := model.Slot{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&slot); err != nil {
-		log.Println("Bad Request")
-		writeError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 	defer r.Body.Close()
-
-	slot.Id, _ = strconv.Atoi(ps.ByName("slot_id"))
-	slot.PersonId, _ = strconv.Atoi(ps.ByName("interviewer_id"))
-
-	query := "UPDATE slots SET initial_time = ?, final_time = ? WHERE id = ?"
-	_, err := db.Exec(query, slot.InitialTime, slot.FinalTime, slot.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.SetDefaults()
+	if err := slot.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	query = "DELETE FROM slots_weekdays WHERE slot_id = ?"
-	_, err = db.Exec(query, slot.Id)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	slot.Id, _ = strconv.Atoi(ps.ByName("slot_id"))
+	slot.PersonId, _ = strconv.Atoi(ps.ByName("interviewer_id"))
+	if err := slots.UpdateSlot(slot); err != nil {
+		writeStoreError(w, err)
 		return
 	}
-
-	for _, weekday := range slot.Weekdays {
-		query = "INSERT INTO slots_weekdays SET slot_id = ?, weekday = ?;"
-		_, err = db.Exec(query, slot.Id, weekday)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-			return
-		}
-	}
-
 	writeJSON(w, http.StatusOK, slot)
 	return
 }
 
-func DeleteInterviewerSlot(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	slotId := ps.ByName("slot_id")
-	query := "DELETE FROM slots_weekdays WHERE slot_id = ?"
-	_, err := db.Exec(query, slotId)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-		return
-	}
-
-	query = "DELETE FROM slots WHERE id = ?"
-	_, err = db.Exec(query, slotId)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+func DeleteInterviewerSlot(slots store.SlotStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	slotId, _ := strconv.Atoi(ps.ByName("slot_id"))
+	if err := slots.DeleteSlot(slotId); err != nil {
+		writeStoreError(w, err)
 		return
 	}
-
 	writeJSON(w, http.StatusOK, nil)
 	return
 }
@@ -0,0 +1,305 @@
+package routes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/scheduling"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+func AddInterview(s store.Store, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	booking := model.Booking{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&booking); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
+	// Stored in UTC with second precision
+	booking.Start = booking.Start.UTC().Truncate(time.Second)
+	booking.End = booking.End.UTC().Truncate(time.Second)
+	// Interviews are confirmed unless only proposed to the people involved
+	if booking.Status == "" {
+		booking.Status = model.BookingConfirmed
+	}
+	if err := booking.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The existing bookings are checked by the store, which reports them
+	candidateAvailability, interviewersAvailability, err := participants(s, booking.Candidate, booking.Interviewers, false)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	period := scheduling.Period{Start: booking.Start, End: booking.End}
+	if !scheduling.Available(period, candidateAvailability, interviewersAvailability) {
+		writeError(w, http.StatusUnprocessableEntity, "the candidate and the interviewers are not all available at that time")
+		return
+	}
+
+	if err = s.AddBooking(&booking); err != nil {
+		var conflict *store.ConflictError
+		if errors.As(err, &conflict) {
+			writeConflict(w, conflict)
+			return
+		}
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, booking)
+}
+
+func GetInterview(bookings store.BookingStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, _ := strconv.Atoi(ps.ByName("interview_id"))
+	booking, err := bookings.GetBooking(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if booking.History, err = bookings.GetBookingHistory(id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, booking)
+}
+
+// The interviews can be filtered by status, as in ?status=proposed,confirmed
+func GetAllInterviews(bookings store.BookingStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	statuses := []string{}
+	for _, value := range r.URL.Query()["status"] {
+		for _, status := range strings.Split(value, ",") {
+			if !model.ValidStatus(status) {
+				err := fmt.Errorf("invalid status %q, use one of %s", status, strings.Join(model.BookingStatuses, ", "))
+				log.Println("Bad Request ::", err.Error())
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	allBookings, err := bookings.GetAllBookings(statuses)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, allBookings)
+}
+
+// UpdateInterviewStatus moves the interview to the Status of the body, as
+// long as its lifecycle allows it
+func UpdateInterviewStatus(bookings store.BookingStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	update := model.Booking{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&update); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
+	if !model.ValidStatus(update.Status) {
+		err := fmt.Errorf("invalid Status %q, use one of %s", update.Status, strings.Join(model.BookingStatuses, ", "))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if update.Status == model.BookingRescheduled {
+		writeError(w, http.StatusBadRequest, "an interview becomes rescheduled when it is moved to a new time")
+		return
+	}
+	id, _ := strconv.Atoi(ps.ByName("interview_id"))
+	setInterviewStatus(bookings, w, id, update.Status)
+}
+
+// Cancelled interviews are kept, with their status changed. Cancelling them
+// again does nothing.
+func CancelInterview(bookings store.BookingStore, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	id, _ := strconv.Atoi(ps.ByName("interview_id"))
+	booking, err := bookings.GetBooking(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if booking.Status == model.BookingCancelled {
+		writeJSON(w, http.StatusOK, booking)
+		return
+	}
+	setInterviewStatus(bookings, w, id, model.BookingCancelled)
+}
+
+// Answers with the updated interview, or a 409 when the transition is not
+// allowed
+func setInterviewStatus(bookings store.BookingStore, w http.ResponseWriter, id int, status string) {
+	booking, err := bookings.SetBookingStatus(id, status)
+	if err != nil {
+		var transition *model.TransitionError
+		if errors.As(err, &transition) {
+			writeError(w, http.StatusConflict, transition.Error())
+			return
+		}
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, booking)
+}
+
+// RescheduleInterview answers with the alternative times of the interview,
+// the closest to its current time first, or moves it when the request has a
+// Start
+func RescheduleInterview(s store.Store, matching *config.MatchingConfig, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	request := model.RescheduleRequest{}
+	decoder := json.NewDecoder(r.Body)
+	// The body can be left out to get the alternatives of the next days
+	if err := decoder.Decode(&request); err != nil && err != io.EOF {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defer r.Body.Close()
+
+	id, _ := strconv.Atoi(ps.ByName("interview_id"))
+	booking, err := s.GetBooking(id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if err = model.CheckTransition(booking.Status, model.BookingRescheduled); err != nil {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if len(booking.Interviewers) == 0 {
+		writeError(w, http.StatusUnprocessableEntity, "the interview has no interviewers left")
+		return
+	}
+
+	if request.Start != nil {
+		moveInterview(s, w, booking, request)
+		return
+	}
+
+	location, err := model.LoadLocation(request.TimeZone)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The alternatives last as long as the interview, over the configured
+	// horizon by default
+	matchingRequest := model.SlotMatchingRequest{
+		Step: request.Step,
+		From: request.From,
+		To:   request.To,
+	}
+	if matchingRequest.From == nil && matchingRequest.To == nil {
+		today := model.DateOf(time.Now().In(location))
+		matchingRequest.From = &today
+	}
+	_, step, err := matchingLengths(matchingRequest, matching)
+	duration := booking.End.Sub(booking.Start)
+	if err == nil {
+		err = setDates(&matchingRequest, matching, location)
+	}
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The interview is still booked, so its current time is left out
+	candidateAvailability, interviewersAvailability, err := participants(s, booking.Candidate, booking.Interviewers, true)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	now := time.Now()
+	alternatives := []model.InterviewOption{}
+	for _, option := range interviewOptions(matchingRequest, location, duration, step, candidateAvailability, interviewersAvailability) {
+		if !option.Start.Before(now) {
+			alternatives = append(alternatives, option)
+		}
+	}
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return distance(alternatives[i].Start, booking.Start) < distance(alternatives[j].Start, booking.Start)
+	})
+	if len(alternatives) > maxOptions {
+		alternatives = alternatives[:maxOptions]
+	}
+	writeJSON(w, http.StatusOK, alternatives)
+}
+
+// Moves the booking to the Start and End of the request, once everyone is
+// checked to be available then
+func moveInterview(s store.Store, w http.ResponseWriter, booking model.Booking, request model.RescheduleRequest) {
+	moved := booking
+	moved.Start = request.Start.UTC().Truncate(time.Second)
+	moved.End = moved.Start.Add(booking.End.Sub(booking.Start))
+	if request.End != nil {
+		moved.End = request.End.UTC().Truncate(time.Second)
+	}
+	if err := moved.ValidateTimes(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	candidateAvailability, interviewersAvailability, err := participants(s, booking.Candidate, booking.Interviewers, false)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	period := scheduling.Period{Start: moved.Start, End: moved.End}
+	if !scheduling.Available(period, candidateAvailability, interviewersAvailability) {
+		writeError(w, http.StatusUnprocessableEntity, "the candidate and the interviewers are not all available at that time")
+		return
+	}
+
+	if moved, err = s.MoveBooking(booking.Id, moved.Start, moved.End); err != nil {
+		var conflict *store.ConflictError
+		var transition *model.TransitionError
+		switch {
+		case errors.As(err, &conflict):
+			writeConflict(w, conflict)
+		case errors.As(err, &transition):
+			writeError(w, http.StatusConflict, transition.Error())
+		default:
+			writeStoreError(w, err)
+		}
+		return
+	}
+	if moved.History, err = s.GetBookingHistory(moved.Id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, moved)
+}
+
+// How far apart two times are
+func distance(a, b time.Time) time.Duration {
+	if a.Before(b) {
+		return b.Sub(a)
+	}
+	return a.Sub(b)
+}
+
+// A 409 with the booking in the way
+func writeConflict(w http.ResponseWriter, conflict *store.ConflictError) {
+	writeJSON(w, http.StatusConflict, map[string]interface{}{
+		"error":   fmt.Sprintf("the interview overlaps interview %d", conflict.Booking.Id),
+		"booking": conflict.Booking,
+	})
+}
@@ -0,0 +1,129 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/scheduling"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
+)
+
+// LoopMatching answers with the itineraries of an interview loop, its
+// rounds in order on the same day, each led by an interviewer of its pool
+func LoopMatching(s store.Store, matching *config.MatchingConfig, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	request := model.LoopRequest{}
+	decoder := json.NewDecoder(r.Body)
+	if err := decoder.Decode(&request); err != nil {
+		log.Println("Bad Request")
+		writeError(w, http.StatusBadRequest, http.StatusText(http.StatusBadRequest))
+		return
+	}
+	defer r.Body.Close()
+
+	if err := request.Validate(); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	duration, step, err := matchingLengths(request.SlotMatchingRequest, matching)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	location, err := model.LoadLocation(request.TimeZone)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	from, to, err := dateRange(request.From, request.To, matching, location)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rounds, err := loopRounds(request.Rounds, duration)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Everyone's availability at once, the interviewers of every round
+	// first, then the ones of each pool
+	interviewers := append([]model.Interviewer{}, request.Interviewers...)
+	pooled := map[int]bool{}
+	for _, round := range request.Rounds {
+		for _, interviewer := range round.Interviewers {
+			if !pooled[interviewer.Id] {
+				pooled[interviewer.Id] = true
+				interviewers = append(interviewers, interviewer)
+			}
+		}
+	}
+	candidateAvailability, interviewersAvailability, err := participants(s, request.Candidate, interviewers, true)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	byId := map[int]scheduling.Availability{}
+	for i, interviewer := range interviewers {
+		byId[interviewer.Id] = interviewersAvailability[i]
+	}
+	for i, round := range request.Rounds {
+		for _, interviewer := range round.Interviewers {
+			rounds[i].Pool = append(rounds[i].Pool, scheduling.Interviewer{Id: interviewer.Id, Availability: byId[interviewer.Id]})
+		}
+	}
+
+	matches := scheduling.MatchLoop(from, to, location, step, candidateAvailability, interviewersAvailability[:len(request.Interviewers)], rounds)
+	if len(matches) > maxOptions {
+		matches = matches[:maxOptions]
+	}
+	itineraries := []model.Itinerary{}
+	for _, match := range matches {
+		itinerary := model.Itinerary{
+			Start: match[0].Start.In(location),
+			End:   match[len(match)-1].End.In(location),
+		}
+		for i, assignment := range match {
+			itinerary.Rounds = append(itinerary.Rounds, model.RoundAssignment{
+				Name:        request.Rounds[i].Name,
+				Interviewer: model.Interviewer{Id: assignment.Interviewer},
+				Start:       assignment.Start.In(location),
+				End:         assignment.End.In(location),
+			})
+		}
+		itineraries = append(itineraries, itinerary)
+	}
+	writeJSON(w, http.StatusOK, itineraries)
+}
+
+// The durations and breaks of the rounds, the duration defaulting to the
+// one of the request
+func loopRounds(requested []model.Round, duration time.Duration) ([]scheduling.Round, error) {
+	rounds := []scheduling.Round{}
+	for number, round := range requested {
+		current := scheduling.Round{Duration: duration}
+		var err error
+		if round.Duration != "" {
+			if current.Duration, err = time.ParseDuration(round.Duration); err != nil || !wholeMinutes(current.Duration) {
+				return nil, fmt.Errorf("Duration %q of round %d must be a whole number of minutes such as \"45m\"", round.Duration, number+1)
+			}
+		}
+		if round.Break != "" {
+			if current.Break, err = time.ParseDuration(round.Break); err != nil || current.Break < 0 {
+				return nil, fmt.Errorf("Break %q of round %d must be a duration such as \"15m\"", round.Break, number+1)
+			}
+		}
+		rounds = append(rounds, current)
+	}
+	return rounds, nil
+}
@@ -1,17 +1,22 @@
 package routes
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/scheduling"
+	"github.com/paulofeitor/kilabs-api/app/store"
+	"github.com/paulofeitor/kilabs-api/config"
 )
 
-func SlotMatching(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+func SlotMatching(s store.Store, matching *config.MatchingConfig, w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	request := model.SlotMatchingRequest{}
 	decoder := json.NewDecoder(r.Body)
 	if err := decoder.Decode(&request); err != nil {
@@ -21,207 +26,235 @@ func SlotMatching(db *sql.DB, w http.ResponseWriter, r *http.Request, ps httprou
 	}
 	defer r.Body.Close()
 
-	candidateSlots, err := getCandidateSlots(db, request.Candidate.Id)
+	duration, step, err := matchingLengths(request, matching)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	location, err := model.LoadLocation(request.TimeZone)
+	if err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err = setDates(&request, matching, location); err != nil {
+		log.Println("Bad Request ::", err.Error())
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	validSlots := []model.Slot{}
-	for _, candidateSlot := range candidateSlots {
-		validSlotForCandidateSlot := model.Slot{}
-		for _, interviewer := range request.Interviewers {
-			interviewerSlots, err := getInterviewerSlots(db, interviewer.Id)
-			if err != nil {
-				writeError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
-				return
-			}
-			interviewerSlotFound := false
-			for _, interviewerSlot := range interviewerSlots {
-				valid := false
-				validSlot := model.Slot{}
-				if validSlotForCandidateSlot.InitialTime == "" {
-					valid, validSlot = match(candidateSlot, interviewerSlot)
-				} else {
-					valid, validSlot = match(validSlotForCandidateSlot, interviewerSlot)
-				}
-				if valid {
-					interviewerSlotFound = true
-					validSlotForCandidateSlot = validSlot
-				}
-			}
-			if !interviewerSlotFound {
-				validSlotForCandidateSlot = model.Slot{}
-				continue
-			}
-		}
-		if validSlotForCandidateSlot.InitialTime != "" {
-			validSlots = append(validSlots, validSlotForCandidateSlot)
+	candidateAvailability, interviewersAvailability, err := participants(s, request.Candidate, request.Interviewers, true)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	if request.From != nil {
+		options := interviewOptions(request, location, duration, step, candidateAvailability, interviewersAvailability)
+		if len(options) > maxOptions {
+			options = options[:maxOptions]
 		}
+		writeJSON(w, http.StatusOK, options)
+		return
+	}
+
+	matches := scheduling.Match(time.Now(), location, candidateAvailability, interviewersAvailability)
+	matches = scheduling.Fit(matches, duration, step)
+	if len(matches) > maxOptions {
+		matches = matches[:maxOptions]
+	}
+	validSlots := []model.Slot{}
+	for _, match := range matches {
+		validSlots = append(validSlots, model.Slot{
+			InitialTime: model.NewTimeOfDay(match.Start),
+			FinalTime:   model.NewTimeOfDay(match.End),
+			Weekdays:    match.Weekdays,
+		})
 	}
-	validSlots = splitSlots(validSlots)
 	writeJSON(w, http.StatusOK, validSlots)
 }
 
-func getCandidateSlots(db *sql.DB, candidateId int) ([]model.Slot, error) {
-	slots := []model.Slot{}
-	query := "SELECT id, initial_time, final_time FROM slots WHERE candidate_id = ?"
-	rows, err := db.Query(query, candidateId)
-	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		return slots, err
+// The interview times from the From to the To date of the request, sorted
+// chronologically in location
+func interviewOptions(request model.SlotMatchingRequest, location *time.Location, duration, step time.Duration, candidate scheduling.Availability, interviewers []scheduling.Availability) []model.InterviewOption {
+	matches := scheduling.MatchDates(*request.From, *request.To, location, candidate, interviewers)
+	matches = scheduling.FitDates(matches, location, duration, step)
+	options := []model.InterviewOption{}
+	for _, match := range matches {
+		period := match.Period(location)
+		options = append(options, model.InterviewOption{Start: period.Start, End: period.End})
 	}
-	defer rows.Close()
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].Start.Before(options[j].Start)
+	})
+	return options
+}
 
-	for rows.Next() {
-		slot := model.Slot{}
-		err = rows.Scan(&slot.Id, &slot.InitialTime, &slot.FinalTime)
+// The availability of the candidate and of each interviewer, less their
+// active bookings when asked to, and less the interviewers' holds for other
+// candidates
+func participants(s store.Store, candidate model.Candidate, interviewers []model.Interviewer, bookings bool) (scheduling.Availability, []scheduling.Availability, error) {
+	interviewersAvailability := []scheduling.Availability{}
+	candidate, err := s.GetCandidate(candidate.Id)
+	if err != nil {
+		return scheduling.Availability{}, interviewersAvailability, err
+	}
+	candidateAvailability, err := availability(s, store.CandidateOwner, candidate.Id, candidate.TimeZone, bookings)
+	if err != nil {
+		return candidateAvailability, interviewersAvailability, err
+	}
+	for _, requested := range interviewers {
+		interviewer, err := s.GetInterviewer(requested.Id)
 		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			return slots, err
+			return candidateAvailability, interviewersAvailability, err
 		}
-
-		weekdays := []time.Weekday{}
-		query = "SELECT weekday FROM slots_weekdays WHERE slot_id = ?"
-		rowsWeekdays, err := db.Query(query, slot.Id)
+		interviewerAvailability, err := availability(s, store.InterviewerOwner, interviewer.Id, interviewer.TimeZone, bookings)
 		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			return slots, err
+			return candidateAvailability, interviewersAvailability, err
 		}
-		defer rowsWeekdays.Close()
-		for rowsWeekdays.Next() {
-			var weekday time.Weekday
-			err = rowsWeekdays.Scan(&weekday)
-			if err != nil {
-				log.Println("Database Scan Error ::", err.Error())
-				return slots, err
-			}
-			weekdays = append(weekdays, weekday)
+		held, err := heldPeriods(s, interviewer.Id, candidate.Id, time.Now())
+		if err != nil {
+			return candidateAvailability, interviewersAvailability, err
 		}
-		slot.Weekdays = weekdays
-		slots = append(slots, slot)
+		interviewerAvailability.Busy = append(interviewerAvailability.Busy, held...)
+		interviewersAvailability = append(interviewersAvailability, interviewerAvailability)
 	}
-	return slots, nil
+	return candidateAvailability, interviewersAvailability, nil
 }
 
-func getInterviewerSlots(db *sql.DB, interviewerId int) ([]model.Slot, error) {
-	slots := []model.Slot{}
-	query := "SELECT id, initial_time, final_time FROM slots WHERE interviewer_id = ?"
-	rows, err := db.Query(query, interviewerId)
+// The slots of a person in the wall clock of their time zone, and their
+// exceptions, and active bookings when asked to, as busy periods
+func availability(slots availabilityStore, owner store.Owner, personId int, timeZone string, bookings bool) (scheduling.Availability, error) {
+	availability := scheduling.Availability{}
+	location, err := model.LoadLocation(timeZone)
 	if err != nil {
-		log.Println("Database Query Error ::", err.Error())
-		return slots, err
+		log.Println("Time Zone Error ::", err.Error())
+		return availability, err
 	}
-	defer rows.Close()
-
-	for rows.Next() {
-		slot := model.Slot{}
-		err = rows.Scan(&slot.Id, &slot.InitialTime, &slot.FinalTime)
-		if err != nil {
-			log.Println("Database Scan Error ::", err.Error())
-			return slots, err
-		}
-
-		weekdays := []time.Weekday{}
-		query = "SELECT weekday FROM slots_weekdays WHERE slot_id = ?"
-		rowsWeekdays, err := db.Query(query, slot.Id)
-		if err != nil {
-			log.Println("Database Query Error ::", err.Error())
-			return slots, err
-		}
-		defer rowsWeekdays.Close()
-		for rowsWeekdays.Next() {
-			var weekday time.Weekday
-			err = rowsWeekdays.Scan(&weekday)
+	personSlots, err := slots.GetSlots(owner, personId)
+	if err != nil {
+		return availability, err
+	}
+	availability.Location = location
+	for _, slot := range personSlots {
+		if slot.RRule != "" {
+			rule, err := slot.Rule(location)
 			if err != nil {
-				log.Println("Database Scan Error ::", err.Error())
-				return slots, err
+				log.Println("RRule Error ::", err.Error())
+				return availability, err
 			}
-			weekdays = append(weekdays, weekday)
+			availability.Recurring = append(availability.Recurring, scheduling.RecurringWindow{
+				Rule:  rule,
+				First: *slot.Date,
+				Start: slot.InitialTime.Duration(),
+				End:   slot.FinalTime.Duration(),
+			})
+			continue
+		}
+		if slot.Date != nil {
+			availability.Dates = append(availability.Dates, scheduling.DatedWindow{
+				Date:  *slot.Date,
+				Start: slot.InitialTime.Duration(),
+				End:   slot.FinalTime.Duration(),
+			})
+			continue
+		}
+		availability.Windows = append(availability.Windows, scheduling.Window{
+			Start:    slot.InitialTime.Duration(),
+			End:      slot.FinalTime.Duration(),
+			Weekdays: slot.Weekdays,
+		})
+	}
+	exceptions, err := slots.GetExceptions(owner, personId)
+	if err != nil {
+		return availability, err
+	}
+	for _, exception := range exceptions {
+		availability.Busy = append(availability.Busy, scheduling.Period{
+			Start: exception.Start(location),
+			End:   exception.End(location),
+		})
+	}
+	if !bookings {
+		return availability, nil
+	}
+	personBookings, err := slots.GetBookings(owner, personId)
+	if err != nil {
+		return availability, err
+	}
+	for _, booking := range personBookings {
+		if booking.Active() {
+			availability.Busy = append(availability.Busy, scheduling.Period{Start: booking.Start, End: booking.End})
 		}
-		slot.Weekdays = weekdays
-		slots = append(slots, slot)
 	}
-	return slots, nil
+	return availability, nil
 }
 
-func timeBefore(h1, h2 string) bool {
-	h1t, _ := time.Parse("15:04:05", h1)
-	h2t, _ := time.Parse("15:04:05", h2)
-	return h1t.Before(h2t)
+type availabilityStore interface {
+	store.SlotStore
+	store.ExceptionStore
+	store.BookingStore
 }
 
-// Match slots hours
-func match(s1, s2 model.Slot) (bool, model.Slot) {
-	slot := model.Slot{}
-	found := false
-	matchingWeekdays := weekdayMatch(s1.Weekdays, s2.Weekdays)
-	if len(matchingWeekdays) != 0 {
-		if timeBefore(s1.InitialTime, s2.FinalTime) && timeBefore(s2.InitialTime, s1.FinalTime) {
-			if timeBefore(s1.InitialTime, s2.InitialTime) {
-				slot.InitialTime = s2.InitialTime
-			} else {
-				slot.InitialTime = s1.InitialTime
-			}
-			if timeBefore(s1.FinalTime, s2.FinalTime) {
-				slot.FinalTime = s1.FinalTime
-			} else {
-				slot.FinalTime = s2.FinalTime
-			}
-			slot.Weekdays = matchingWeekdays
-			found = true
-		}
+// Longest period of a dated slot matching
+const maxMatchingDays = 366
+
+// Most slots, interview options or itineraries answered by a matching, the
+// first ones in the order of the response
+const maxOptions = 1000
+
+// Fills the missing From or To date, From being today in location and To
+// the configured horizon after From, and checks the period is not longer
+// than maxMatchingDays
+func setDates(request *model.SlotMatchingRequest, matching *config.MatchingConfig, location *time.Location) error {
+	if request.From == nil && request.To == nil {
+		return nil
 	}
-	return found, slot
+	from, to, err := dateRange(request.From, request.To, matching, location)
+	request.From, request.To = &from, &to
+	return err
 }
 
-// Match slots weekdays
-func weekdayMatch(w1, w2 []time.Weekday) []time.Weekday {
-	mathingWeekdays := []time.Weekday{}
-	for _, w1d := range w1 {
-		for _, w2d := range w2 {
-			if w1d == w2d {
-				mathingWeekdays = append(mathingWeekdays, w1d)
-			}
-		}
+// The dates from from to to, both included, the missing ones filled like
+// setDates does
+func dateRange(from, to *model.Date, matching *config.MatchingConfig, location *time.Location) (model.Date, model.Date, error) {
+	if from == nil {
+		today := model.DateOf(time.Now().In(location))
+		from = &today
+	}
+	if to == nil {
+		horizon := from.AddDays(matching.HorizonDays - 1)
+		to = &horizon
 	}
-	return mathingWeekdays
+	if to.Before(*from) {
+		return *from, *to, errors.New("To must not be before From")
+	}
+	if from.AddDays(maxMatchingDays).Before(to.AddDays(1)) {
+		return *from, *to, fmt.Errorf("From and To can't be more than %d days apart", maxMatchingDays)
+	}
+	return *from, *to, nil
 }
 
-// Split Slots to 1-hour collection and separated weekdays
-func splitSlots(slots []model.Slot) []model.Slot {
-	returningSlots := []model.Slot{}
-	weekdayTreated := []model.Slot{}
-	for _, slot := range slots {
-		for {
-			if len(slot.Weekdays) > 1 {
-				newWeekdays := []time.Weekday{slot.Weekdays[0]}
-				slot.Weekdays = append(slot.Weekdays[:0], slot.Weekdays[1:]...)
-				newSlot := model.Slot{InitialTime: slot.InitialTime, FinalTime: slot.FinalTime, Weekdays: newWeekdays}
-				weekdayTreated = append(weekdayTreated, newSlot)
-			} else {
-				weekdayTreated = append(weekdayTreated, slot)
-				break
-			}
+// Interview duration and start step of the request, falling back to the
+// configured ones. Both are whole minutes, so that a request can't ask for
+// a start time every nanosecond.
+func matchingLengths(request model.SlotMatchingRequest, matching *config.MatchingConfig) (time.Duration, time.Duration, error) {
+	duration, step := matching.Duration, matching.Step
+	var err error
+	if request.Duration != "" {
+		if duration, err = time.ParseDuration(request.Duration); err != nil || !wholeMinutes(duration) {
+			return 0, 0, fmt.Errorf("Duration %q must be a whole number of minutes such as \"30m\" or \"1h30m\"", request.Duration)
 		}
 	}
-
-	for _, slot := range weekdayTreated {
-		for {
-			s1i, _ := time.Parse("15:04:05", slot.InitialTime)
-			s1f, _ := time.Parse("15:04:05", slot.FinalTime)
-			if s1i.Add(time.Duration(1) * time.Hour).Before(s1f) {
-				newSlot := model.Slot{}
-				newSlot.InitialTime = slot.InitialTime
-				newSlot.FinalTime = s1i.Add(time.Duration(1) * time.Hour).Format("15:04:05")
-				newSlot.Weekdays = slot.Weekdays
-				returningSlots = append(returningSlots, newSlot)
-				slot.InitialTime = newSlot.FinalTime
-			} else {
-				returningSlots = append(returningSlots, slot)
-				break
-			}
+	if request.Step != "" {
+		if step, err = time.ParseDuration(request.Step); err != nil || !wholeMinutes(step) {
+			return 0, 0, fmt.Errorf("Step %q must be a whole number of minutes such as \"15m\"", request.Step)
 		}
 	}
-	return returningSlots
+	return duration, step, nil
+}
+
+func wholeMinutes(d time.Duration) bool {
+	return d >= time.Minute && d%time.Minute == 0
 }
@@ -0,0 +1,476 @@
+// Package rrule parses the RFC 5545 recurrence rules of the slots and
+// expands them into the dates they happen on. Slots have a time of the day
+// of their own, so rules are only evaluated at the granularity of a day.
+//
+// FREQ (DAILY, WEEKLY, MONTHLY or YEARLY), INTERVAL, COUNT, UNTIL, BYDAY,
+// BYMONTHDAY, BYMONTH and WKST are supported, which is enough for "every
+// other Friday", "first Monday of the month" or "weekly until March 31".
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+var frequencies = map[string]Frequency{
+	"DAILY":   Daily,
+	"WEEKLY":  Weekly,
+	"MONTHLY": Monthly,
+	"YEARLY":  Yearly,
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+var frequencyNames = map[Frequency]string{
+	Daily:   "DAILY",
+	Weekly:  "WEEKLY",
+	Monthly: "MONTHLY",
+	Yearly:  "YEARLY",
+}
+
+var weekdayNames = map[time.Weekday]string{
+	time.Sunday:    "SU",
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+}
+
+// WeekdayNum is a BYDAY entry. Ordinal picks a single one of the weekdays of
+// the month or year, 1 being the first and -1 the last, while 0 keeps them
+// all.
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed RRULE. Dates are midnights in UTC and a zero Until means
+// the rule has no end date.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+	// From the optional DTSTART line, zero when there is none
+	Start time.Time
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR", optionally
+// prefixed by "RRULE:" and preceded by a "DTSTART:20261106" line, for
+// occurrences in UTC.
+func Parse(value string) (*Rule, error) {
+	return ParseIn(value, time.UTC)
+}
+
+// ParseIn is Parse for occurrences on the days of location: a UTC UNTIL,
+// such as "UNTIL=20261231T230000Z", ends the rule on its day there.
+func ParseIn(value string, location *time.Location) (*Rule, error) {
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	found := false
+	for _, line := range strings.Split(strings.TrimSpace(value), "\n") {
+		line = strings.TrimSpace(line)
+		upper := strings.ToUpper(line)
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(upper, "DTSTART"):
+			separator := strings.LastIndex(line, ":")
+			if separator < 0 {
+				return nil, fmt.Errorf("rrule: invalid DTSTART %q", line)
+			}
+			start, err := parseDate(line[separator+1:])
+			if err != nil {
+				return nil, fmt.Errorf("rrule: invalid DTSTART: %v", err)
+			}
+			rule.Start = start
+		default:
+			if found {
+				return nil, errors.New("rrule: only one RRULE is supported")
+			}
+			if err := rule.parseParts(strings.TrimPrefix(upper, "RRULE:"), location); err != nil {
+				return nil, err
+			}
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.New("rrule: FREQ is required")
+	}
+	return rule, nil
+}
+
+func (r *Rule) parseParts(value string, location *time.Location) error {
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		separator := strings.Index(part, "=")
+		if separator < 0 {
+			return fmt.Errorf("rrule: invalid part %q, expected NAME=VALUE", part)
+		}
+		name, value := part[:separator], part[separator+1:]
+		if seen[name] {
+			return fmt.Errorf("rrule: %s is given twice", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			var ok bool
+			if r.Freq, ok = frequencies[value]; !ok {
+				err = fmt.Errorf("unsupported frequency %q, use DAILY, WEEKLY, MONTHLY or YEARLY", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = parseInt(value, 1, 1000)
+		case "COUNT":
+			r.Count, err = parseInt(value, 1, 10000)
+		case "UNTIL":
+			r.Until, err = parseUntil(value, location)
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		case "BYMONTHDAY":
+			r.ByMonthDay, err = parseList(value, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseList(value, 1, 12)
+			for _, month := range months {
+				r.ByMonth = append(r.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			var ok bool
+			if r.WeekStart, ok = weekdays[value]; !ok {
+				err = fmt.Errorf("invalid weekday %q", value)
+			}
+		case "BYSETPOS", "BYYEARDAY", "BYWEEKNO", "BYHOUR", "BYMINUTE", "BYSECOND":
+			return fmt.Errorf("rrule: %s is not supported", name)
+		default:
+			return fmt.Errorf("rrule: unknown rule part %s", name)
+		}
+		if err != nil {
+			return fmt.Errorf("rrule: %s: %v", name, err)
+		}
+	}
+
+	if !seen["FREQ"] {
+		return errors.New("rrule: FREQ is required")
+	}
+	if seen["COUNT"] && seen["UNTIL"] {
+		return errors.New("rrule: COUNT and UNTIL can't be used together")
+	}
+	if r.Freq == Weekly && len(r.ByMonthDay) > 0 {
+		return errors.New("rrule: BYMONTHDAY can't be used with FREQ=WEEKLY")
+	}
+	for _, day := range r.ByDay {
+		if day.Ordinal != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return errors.New("rrule: numbered BYDAY values need FREQ=MONTHLY or FREQ=YEARLY")
+		}
+	}
+	return nil
+}
+
+// String writes the rule back as an RRULE value, without its DTSTART.
+func (r *Rule) String() string {
+	return r.Format(nil)
+}
+
+// Format writes the rule as an RRULE value, without its DTSTART. Given the
+// location of the occurrences, UNTIL is written as the end of its day there
+// in UTC, as RFC 5545 requires next to a DTSTART with a time zone, and as a
+// date otherwise.
+func (r *Rule) Format(location *time.Location) string {
+	parts := []string{"FREQ=" + frequencyNames[r.Freq]}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		until := r.Until.Format("20060102")
+		if location != nil {
+			end := time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 23, 59, 59, 0, location)
+			until = end.UTC().Format("20060102T150405Z")
+		}
+		parts = append(parts, "UNTIL="+until)
+	}
+	if len(r.ByDay) > 0 {
+		days := []string{}
+		for _, day := range r.ByDay {
+			name := weekdayNames[day.Weekday]
+			if day.Ordinal != 0 {
+				name = strconv.Itoa(day.Ordinal) + name
+			}
+			days = append(days, name)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := []int{}
+		for _, month := range r.ByMonth {
+			months = append(months, int(month))
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayNames[r.WeekStart])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the dates from from to to, both included, on which the
+// rule starting on start happens. Only the dates matching the rule count,
+// start itself is skipped when it doesn't.
+func (r *Rule) Between(start, from, to time.Time) []time.Time {
+	start, from, to = date(start), date(from), date(to)
+	dates := []time.Time{}
+	count := 0
+	for period := 0; ; period += r.Interval {
+		periodStart := r.periodStart(start, period)
+		if periodStart.After(to) || (!r.Until.IsZero() && periodStart.After(r.Until)) {
+			return dates
+		}
+		for _, day := range r.expand(start, periodStart) {
+			if day.Before(start) {
+				continue
+			}
+			if day.After(to) || (!r.Until.IsZero() && day.After(r.Until)) {
+				return dates
+			}
+			if !day.Before(from) {
+				dates = append(dates, day)
+			}
+			count++
+			if r.Count > 0 && count >= r.Count {
+				return dates
+			}
+		}
+	}
+}
+
+// First day of the period-th day, week, month or year from the one of start
+func (r *Rule) periodStart(start time.Time, period int) time.Time {
+	switch r.Freq {
+	case Weekly:
+		offset := (int(start.Weekday()) - int(r.WeekStart) + 7) % 7
+		return start.AddDate(0, 0, 7*period-offset)
+	case Monthly:
+		return time.Date(start.Year(), start.Month()+time.Month(period), 1, 0, 0, 0, 0, time.UTC)
+	case Yearly:
+		return time.Date(start.Year()+period, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return start.AddDate(0, 0, period)
+}
+
+// The days of the period starting on periodStart that match the rule, in
+// order
+func (r *Rule) expand(start, periodStart time.Time) []time.Time {
+	var end time.Time
+	switch r.Freq {
+	case Daily:
+		end = periodStart.AddDate(0, 0, 1)
+	case Weekly:
+		end = periodStart.AddDate(0, 0, 7)
+	case Monthly:
+		end = periodStart.AddDate(0, 1, 0)
+	case Yearly:
+		end = periodStart.AddDate(1, 0, 0)
+	}
+	days := []time.Time{}
+	for day := periodStart; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if r.matches(start, day) {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *Rule) matches(start, day time.Time) bool {
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, day.Month()) {
+		return false
+	}
+	if len(r.ByMonthDay) > 0 && !r.matchesMonthDay(day) {
+		return false
+	}
+	if len(r.ByDay) > 0 && !r.matchesDay(day) {
+		return false
+	}
+	// Without BYxxx parts the rule repeats the day of start
+	switch r.Freq {
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return day.Weekday() == start.Weekday()
+		}
+	case Monthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			return day.Day() == start.Day()
+		}
+	case Yearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+				return false
+			}
+			return day.Day() == start.Day()
+		}
+	}
+	return true
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range r.ByMonthDay {
+		if monthDay == day.Day() || last+monthDay+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// Numbered weekdays count within the month, or within the year for yearly
+// rules without BYMONTH
+func (r *Rule) matchesDay(day time.Time) bool {
+	first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
+	if r.Freq == Yearly && len(r.ByMonth) == 0 {
+		first = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		last = time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+	}
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday != day.Weekday() {
+			continue
+		}
+		switch {
+		case byDay.Ordinal == 0:
+			return true
+		case byDay.Ordinal > 0 && daysBetween(first, day)/7+1 == byDay.Ordinal:
+			return true
+		case byDay.Ordinal < 0 && daysBetween(day, last)/7+1 == -byDay.Ordinal:
+			return true
+		}
+	}
+	return false
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(to.Sub(from).Hours()+12) / 24
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// Midnight in UTC of the day of t in its own location
+func date(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Accepts "20270331" and date-times such as "20270331T235959Z", keeping the
+// date only
+func parseDate(value string) (time.Time, error) {
+	if len(value) > 8 && value[8] == 'T' {
+		value = value[:8]
+	}
+	parsed, err := time.Parse("20060102", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYYMMDD", value)
+	}
+	return parsed, nil
+}
+
+// A UTC date-time such as "20261231T230000Z" on its day in location, and
+// the other values like parseDate
+func parseUntil(value string, location *time.Location) (time.Time, error) {
+	if !strings.HasSuffix(value, "Z") {
+		return parseDate(value)
+	}
+	parsed, err := time.Parse("20060102T150405Z", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYYMMDD or YYYYMMDDTHHMMSSZ", value)
+	}
+	return date(parsed.In(location)), nil
+}
+
+func parseInt(value string, min, max int) (int, error) {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min || parsed > max {
+		return 0, fmt.Errorf("%q must be a number from %d to %d", value, min, max)
+	}
+	return parsed, nil
+}
+
+// A comma separated list of non zero numbers from min to max
+func parseList(value string, min, max int) ([]int, error) {
+	list := []int{}
+	for _, item := range strings.Split(value, ",") {
+		parsed, err := parseInt(item, min, max)
+		if err != nil || parsed == 0 {
+			return nil, fmt.Errorf("%q must be a number from %d to %d", item, min, max)
+		}
+		list = append(list, parsed)
+	}
+	return list, nil
+}
+
+func joinInts(values []int) string {
+	items := []string{}
+	for _, value := range values {
+		items = append(items, strconv.Itoa(value))
+	}
+	return strings.Join(items, ",")
+}
+
+// Weekdays such as "MO", "2TU" or "-1FR"
+func parseByDay(value string) ([]WeekdayNum, error) {
+	days := []WeekdayNum{}
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		weekday, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+		day := WeekdayNum{Weekday: weekday}
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			parsed, err := strconv.Atoi(ordinal)
+			if err != nil || parsed == 0 || parsed < -53 || parsed > 53 {
+				return nil, fmt.Errorf("invalid weekday %q", item)
+			}
+			day.Ordinal = parsed
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
@@ -0,0 +1,121 @@
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(value string) time.Time {
+	parsed, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func days(dates []time.Time) string {
+	formatted := []string{}
+	for _, date := range dates {
+		formatted = append(formatted, date.Format("2006-01-02"))
+	}
+	return strings.Join(formatted, " ")
+}
+
+func TestBetween(t *testing.T) {
+	tests := []struct {
+		name            string
+		rule            string
+		start, from, to string
+		want            string
+	}{
+		{"every other week", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", "2026-11-02", "2026-11-02", "2026-11-30", "2026-11-02 2026-11-06 2026-11-16 2026-11-20 2026-11-30"},
+		{"count from the start", "FREQ=DAILY;COUNT=3", "2026-11-02", "2026-11-03", "2026-11-30", "2026-11-03 2026-11-04"},
+		{"until included", "FREQ=WEEKLY;UNTIL=20261116", "2026-11-02", "2026-11-02", "2026-11-30", "2026-11-02 2026-11-09 2026-11-16"},
+		{"start not matching", "FREQ=WEEKLY;BYDAY=WE", "2026-11-02", "2026-11-02", "2026-11-11", "2026-11-04 2026-11-11"},
+		{"last Friday", "FREQ=MONTHLY;BYDAY=-1FR", "2026-11-02", "2026-11-02", "2027-01-31", "2026-11-27 2026-12-25 2027-01-29"},
+		{"first Monday", "FREQ=MONTHLY;BYDAY=1MO", "2026-11-02", "2026-11-02", "2027-01-31", "2026-11-02 2026-12-07 2027-01-04"},
+		{"month days from both ends", "FREQ=MONTHLY;BYMONTHDAY=15,-1", "2026-11-02", "2026-11-02", "2027-01-31", "2026-11-15 2026-11-30 2026-12-15 2026-12-31 2027-01-15 2027-01-31"},
+		{"monthly on a missing day", "FREQ=MONTHLY", "2026-01-31", "2026-01-01", "2026-05-31", "2026-01-31 2026-03-31 2026-05-31"},
+		{"leap days", "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29", "2026-01-01", "2026-01-01", "2032-12-31", "2028-02-29 2032-02-29"},
+		{"last Sunday of October", "FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU", "2026-01-01", "2026-01-01", "2027-12-31", "2026-10-25 2027-10-31"},
+		{"week starting on Sunday", "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO;WKST=SU", "2026-11-01", "2026-11-01", "2026-11-16", "2026-11-01 2026-11-02 2026-11-15 2026-11-16"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := Parse(test.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := days(rule.Between(day(test.start), day(test.from), day(test.to))); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseIn(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 23:00 UTC on December 31st is already January 1st in Berlin
+	rule, err := ParseIn("FREQ=DAILY;UNTIL=20261231T230000Z", berlin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := days(rule.Between(day("2026-12-30"), day("2026-12-30"), day("2027-01-05"))); got != "2026-12-30 2026-12-31 2027-01-01" {
+		t.Errorf("in Berlin got %s, want the occurrences until January 1st", got)
+	}
+	if got := rule.Format(berlin); got != "FREQ=DAILY;UNTIL=20270101T225959Z" {
+		t.Errorf("Format gave %s", got)
+	}
+	if rule, err = Parse("FREQ=DAILY;UNTIL=20261231T230000Z"); err != nil || !rule.Until.Equal(day("2026-12-31")) {
+		t.Errorf("in UTC got %v and %v, want December 31st", rule, err)
+	}
+	// Floating date-times are already on the days of the occurrences
+	if rule, err = ParseIn("FREQ=DAILY;UNTIL=20261231T230000", berlin); err != nil || !rule.Until.Equal(day("2026-12-31")) {
+		t.Errorf("floating got %v and %v, want December 31st", rule, err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	rule, err := Parse("DTSTART:20261102\nRRULE:freq=monthly;BYDAY=-1FR,MO;WKST=SU")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rule.Start.Equal(day("2026-11-02")) {
+		t.Errorf("Start %v, want the DTSTART", rule.Start)
+	}
+	if got := rule.String(); got != "FREQ=MONTHLY;BYDAY=-1FR,MO;WKST=SU" {
+		t.Errorf("String gave %s", got)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ",
+		"FREQ=DAILY;FREQ=WEEKLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=2;UNTIL=20261231",
+		"FREQ=DAILY;UNTIL=2026",
+		"FREQ=DAILY;UNTIL=20261231T2300Z",
+		"FREQ=DAILY;BYDAY=XX",
+		"FREQ=MONTHLY;BYDAY=0MO",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=WEEKLY;BYMONTHDAY=1",
+		"FREQ=MONTHLY;BYMONTHDAY=0",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=YEARLY;BYMONTH=13",
+		"FREQ=DAILY;WKST=XX",
+		"FREQ=DAILY;BYSETPOS=1",
+		"FREQ=DAILY;FOO=1",
+		"DTSTART:2026\nFREQ=DAILY",
+		"FREQ=DAILY\nFREQ=WEEKLY",
+	} {
+		if rule, err := Parse(invalid); err == nil {
+			t.Errorf("Parse(%q) = %s, want an error", invalid, rule)
+		}
+	}
+}
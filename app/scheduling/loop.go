@@ -0,0 +1,179 @@
+package scheduling
+
+import (
+	"sort"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+)
+
+// Interviewer is the Availability of an interviewer known by Id, so that a
+// loop doesn't give them two rounds.
+type Interviewer struct {
+	Id int
+	Availability
+}
+
+// Round is a step of an interview loop, led by one of the interviewers of
+// its Pool for Duration, at least Break after the end of the previous round.
+type Round struct {
+	Pool     []Interviewer
+	Duration time.Duration
+	Break    time.Duration
+}
+
+// Assignment is a round of an itinerary, with the Id of the interviewer
+// leading it.
+type Assignment struct {
+	Interviewer int
+	Period
+}
+
+// MatchLoop returns the itineraries of an interview loop from the from date
+// to the to date, both included: the rounds in order on the same day of
+// location, while the candidate and all the interviewers, who attend every
+// round, are available as MatchDates would find them. Every round starts on
+// a multiple of step of the wall clock, as in FitDates, and is led by a
+// different interviewer of its pool.
+//
+// There is an itinerary per start time of the first round, the following
+// rounds being as early as possible and led by the first available
+// interviewer of their pool. They are sorted chronologically.
+func MatchLoop(from, to model.Date, location *time.Location, step time.Duration, candidate Availability, interviewers []Availability, rounds []Round) [][]Assignment {
+	itineraries := [][]Assignment{}
+	if location == nil {
+		location = time.UTC
+	}
+	if step <= 0 || len(rounds) == 0 {
+		return itineraries
+	}
+	for day := from; !to.Before(day); day = day.AddDays(1) {
+		l := newLoop(day, location, step, candidate, interviewers, rounds)
+		first := rounds[0]
+		for start := l.align(0); start+first.Duration <= l.length; start = l.align(start + 1) {
+			for _, interviewer := range first.Pool {
+				if itinerary := l.lead(0, interviewer, start, map[int]bool{}); itinerary != nil {
+					itineraries = append(itineraries, itinerary)
+					break
+				}
+			}
+		}
+	}
+	return itineraries
+}
+
+// A day of a loop, the intervals being offsets from its midnight
+type loop struct {
+	origin   time.Time
+	length   time.Duration
+	location *time.Location
+	step     time.Duration
+	rounds   []Round
+	// When the candidate and all the interviewers are available
+	common []interval
+	// When each pool interviewer is available too, by Id
+	free map[int][]interval
+}
+
+func newLoop(day model.Date, location *time.Location, step time.Duration, candidate Availability, interviewers []Availability, rounds []Round) *loop {
+	origin, end := day.In(location), day.AddDays(1).In(location)
+	l := &loop{
+		origin:   origin,
+		length:   end.Sub(origin),
+		location: location,
+		step:     step,
+		rounds:   rounds,
+		common:   candidate.between(origin, end),
+		free:     map[int][]interval{},
+	}
+	for _, interviewer := range interviewers {
+		l.common = intersect(l.common, interviewer.between(origin, end))
+	}
+	return l
+}
+
+// The first offset from offset on whose wall clock is a multiple of step
+func (l *loop) align(offset time.Duration) time.Duration {
+	wall := sinceMidnight(l.origin.Add(offset).In(l.location))
+	return offset + (wall+l.step-1)/l.step*l.step - wall
+}
+
+func (l *loop) available(interviewer Interviewer) []interval {
+	free, ok := l.free[interviewer.Id]
+	if !ok {
+		free = intersect(l.common, interviewer.between(l.origin, l.origin.Add(l.length)))
+		l.free[interviewer.Id] = free
+	}
+	return free
+}
+
+// The earliest aligned start from earliest on at which interviewer can lead
+// a round of duration
+func (l *loop) earliest(interviewer Interviewer, earliest, duration time.Duration) (time.Duration, bool) {
+	for _, i := range l.available(interviewer) {
+		if i.end < earliest+duration {
+			continue
+		}
+		start := earliest
+		if i.start > start {
+			start = i.start
+		}
+		if start = l.align(start); start+duration <= i.end {
+			return start, true
+		}
+	}
+	return 0, false
+}
+
+// The itinerary in which interviewer leads the round from start, nil when
+// the following rounds can't be held after it. used holds the interviewers
+// of the previous rounds.
+func (l *loop) lead(round int, interviewer Interviewer, start time.Duration, used map[int]bool) []Assignment {
+	current := l.rounds[round]
+	end := start + current.Duration
+	if used[interviewer.Id] || end > l.length || !covers(l.available(interviewer), start, end) {
+		return nil
+	}
+	assignment := Assignment{
+		Interviewer: interviewer.Id,
+		Period:      Period{Start: l.origin.Add(start), End: l.origin.Add(end)},
+	}
+	if round == len(l.rounds)-1 {
+		return []Assignment{assignment}
+	}
+
+	// The interviewers of the next round by their earliest start, as a
+	// later start of the same interviewer can't make the rest any easier
+	next := l.rounds[round+1]
+	type option struct {
+		interviewer Interviewer
+		start       time.Duration
+	}
+	options := []option{}
+	for _, pooled := range next.Pool {
+		if nextStart, ok := l.earliest(pooled, end+next.Break, next.Duration); ok {
+			options = append(options, option{pooled, nextStart})
+		}
+	}
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].start < options[j].start
+	})
+	used[interviewer.Id] = true
+	defer delete(used, interviewer.Id)
+	for _, option := range options {
+		if rest := l.lead(round+1, option.interviewer, option.start, used); rest != nil {
+			return append([]Assignment{assignment}, rest...)
+		}
+	}
+	return nil
+}
+
+// Whether one of the sorted intervals holds the whole of start to end
+func covers(intervals []interval, start, end time.Duration) bool {
+	for _, i := range intervals {
+		if i.start <= start && end <= i.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,470 @@
+// Package scheduling finds the times in which a candidate and a group of
+// interviewers are all available. It works on plain availability values and
+// knows nothing about HTTP or storage, so it can be used by the API as well
+// as by batch jobs.
+package scheduling
+
+import (
+	"sort"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/rrule"
+)
+
+// Week is the period of the recurring availability.
+const Week = 7 * 24 * time.Hour
+
+// Window is a weekly recurring availability, from Start to End on every
+// one of its Weekdays. Start and End are wall clock offsets from midnight,
+// End being at most 24 hours.
+type Window struct {
+	Start    time.Duration
+	End      time.Duration
+	Weekdays []time.Weekday
+}
+
+// DatedWindow is an availability on a single Date, from Start to End of its
+// wall clock.
+type DatedWindow struct {
+	Date  model.Date
+	Start time.Duration
+	End   time.Duration
+}
+
+// RecurringWindow is an availability from Start to End of the wall clock on
+// every date of Rule, which starts on First.
+type RecurringWindow struct {
+	Rule  *rrule.Rule
+	First model.Date
+	Start time.Duration
+	End   time.Duration
+}
+
+// Period is an absolute span of time, from Start to End.
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Availability holds the weekly, dated and recurring windows of one person,
+// in the wall clock of their Location, and the Busy periods in which they are
+// not available despite their windows. A nil Location is UTC.
+type Availability struct {
+	Windows   []Window
+	Dates     []DatedWindow
+	Recurring []RecurringWindow
+	Busy      []Period
+	Location  *time.Location
+}
+
+// Match returns every window in which the candidate and all the
+// interviewers are available, in the wall clock of location. It only looks
+// at the weekly windows, ignoring the dated and recurring ones. The busy
+// periods of the seven days from reference are taken out of the windows of
+// their weekday, so the windows are the ones free on their next occurrence.
+//
+// Everyone's windows are first placed on the week containing reference,
+// which decides the UTC offsets used while daylight saving time differs
+// between the zones. A window crossing midnight once converted is split in
+// two, one for each weekday. Each returned window is on a single weekday and
+// they are sorted by weekday and start time.
+func Match(reference time.Time, location *time.Location, candidate Availability, interviewers []Availability) []Window {
+	start := weekStart(reference)
+	common := candidate.weekly(reference, start)
+	for _, interviewer := range interviewers {
+		common = intersect(common, interviewer.weekly(reference, start))
+	}
+	return windows(common, start, location)
+}
+
+// MatchDates returns every window from the from date to the to date, both
+// included, in which the candidate and all the interviewers are available,
+// in the wall clock of location. Weekly windows are repeated on every day of
+// their weekdays and recurring windows on every date of their rule, using the
+// UTC offsets of that day, and the busy periods are
+// taken out of the windows. Each returned window is
+// on a single date and they are sorted by date and start time.
+func MatchDates(from, to model.Date, location *time.Location, candidate Availability, interviewers []Availability) []DatedWindow {
+	if location == nil {
+		location = time.UTC
+	}
+	origin, end := from.In(location), to.AddDays(1).In(location)
+	common := candidate.between(origin, end)
+	for _, interviewer := range interviewers {
+		common = intersect(common, interviewer.between(origin, end))
+	}
+	windows := []DatedWindow{}
+	for _, i := range common {
+		split(i, origin, location, func(day time.Time, start, end time.Duration) {
+			windows = append(windows, DatedWindow{Date: model.DateOf(day), Start: start, End: end})
+		})
+	}
+	return windows
+}
+
+// Available tells whether the candidate and all the interviewers are
+// available during the whole period, as MatchDates would find them.
+func Available(period Period, candidate Availability, interviewers []Availability) bool {
+	common := candidate.between(period.Start, period.End)
+	for _, interviewer := range interviewers {
+		common = intersect(common, interviewer.between(period.Start, period.End))
+	}
+	return len(common) == 1 && common[0].start == 0 && common[0].end == period.End.Sub(period.Start)
+}
+
+// FreeBusy is the time of a person split in the periods they are Free, as
+// MatchDates would find them, Busy, during their busy periods, and
+// Unavailable, out of their windows.
+type FreeBusy struct {
+	Free        []Period
+	Busy        []Period
+	Unavailable []Period
+}
+
+// FreeBusy returns the free, busy and unavailable periods of the person
+// during period, sorted and merged.
+func (a Availability) FreeBusy(period Period) FreeBusy {
+	free, busy := a.between(period.Start, period.End), a.busy(period.Start, period.End)
+	whole := []interval{{0, period.End.Sub(period.Start)}}
+	unavailable := subtract(whole, union(append(append([]interval{}, free...), busy...)))
+	return FreeBusy{
+		Free:        periods(free, period.Start),
+		Busy:        periods(busy, period.Start),
+		Unavailable: periods(unavailable, period.Start),
+	}
+}
+
+func periods(intervals []interval, origin time.Time) []Period {
+	periods := []Period{}
+	for _, i := range intervals {
+		periods = append(periods, Period{Start: origin.Add(i.start), End: origin.Add(i.end)})
+	}
+	return periods
+}
+
+// Fit returns every interview of the given duration that fits entirely in
+// one of the windows. Interviews start at multiples of step from midnight,
+// so a step of 15 minutes gives :00, :15, :30 and :45 starts. Each returned
+// window is on a single weekday.
+func Fit(windows []Window, duration, step time.Duration) []Window {
+	interviews := []Window{}
+	if duration <= 0 || step <= 0 {
+		return interviews
+	}
+	for _, window := range windows {
+		first := (window.Start + step - 1) / step * step
+		for _, weekday := range window.Weekdays {
+			for start := first; start+duration <= window.End; start += step {
+				interviews = append(interviews, Window{
+					Start:    start,
+					End:      start + duration,
+					Weekdays: []time.Weekday{weekday},
+				})
+			}
+		}
+	}
+	return interviews
+}
+
+// FitDates is Fit for dated windows in the wall clock of location. The
+// duration is elapsed time, so an interview across a daylight saving time
+// change ends an hour earlier or later on the wall clock. The start times
+// that don't exist that day are left out, and so are the interviews whose
+// wall clock times would be read back as other times.
+func FitDates(windows []DatedWindow, location *time.Location, duration, step time.Duration) []DatedWindow {
+	interviews := []DatedWindow{}
+	if duration <= 0 || step <= 0 {
+		return interviews
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	for _, window := range windows {
+		bounds := window.Period(location)
+		for start := (window.Start + step - 1) / step * step; start < window.End; start += step {
+			begin := wallClock(window.Date.Year, window.Date.Month, window.Date.Day, start, location)
+			end := begin.Add(duration)
+			if end.After(bounds.End) {
+				break
+			}
+			if begin.Before(bounds.Start) || sinceMidnight(begin) != start {
+				continue
+			}
+			interview := DatedWindow{Date: window.Date, Start: start, End: sinceMidnight(end)}
+			if model.DateOf(end) != window.Date {
+				interview.End = 24 * time.Hour
+			}
+			// The wall clock must lead back to the same times
+			if period := interview.Period(location); !period.Start.Equal(begin) || !period.End.Equal(end) {
+				continue
+			}
+			interviews = append(interviews, interview)
+		}
+	}
+	return interviews
+}
+
+// Period returns when the window starts and ends in location.
+func (w DatedWindow) Period(location *time.Location) Period {
+	return Period{
+		Start: wallClock(w.Date.Year, w.Date.Month, w.Date.Day, w.Start, location),
+		End:   wallClock(w.Date.Year, w.Date.Month, w.Date.Day, w.End, location),
+	}
+}
+
+// From start to end, as offsets from the start of the UTC week
+type interval struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// Sunday at midnight UTC of the week containing t
+func weekStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day()-int(t.Weekday()), 0, 0, 0, 0, time.UTC)
+}
+
+// Places the windows on the UTC week starting at start, as sorted and non
+// overlapping intervals. The week repeats, so intervals going past its end
+// continue at its beginning.
+func (a Availability) intervals(start time.Time) []interval {
+	location := a.location()
+	local := start.In(location)
+	intervals := []interval{}
+	for _, window := range a.Windows {
+		if window.Start >= window.End {
+			continue
+		}
+		for _, weekday := range window.Weekdays {
+			if weekday < time.Sunday || weekday > time.Saturday {
+				continue
+			}
+			// The weekday in the person's zone during the same week, west
+			// of UTC the week starting on the local Saturday
+			day := local.Day() + (int(weekday-local.Weekday())+7)%7
+			from := wallClock(local.Year(), local.Month(), day, window.Start, location).Sub(start)
+			to := wallClock(local.Year(), local.Month(), day, window.End, location).Sub(start)
+			intervals = append(intervals, wrap(from, to)...)
+		}
+	}
+	return union(intervals)
+}
+
+// The weekly intervals from start less the busy periods of the seven days
+// from reference, brought inside the week
+func (a Availability) weekly(reference, start time.Time) []interval {
+	busy := []interval{}
+	for _, i := range a.busy(reference, reference.Add(Week)) {
+		offset := reference.Sub(start)
+		busy = append(busy, wrap(i.start+offset, i.end+offset)...)
+	}
+	return subtract(a.intervals(start), union(busy))
+}
+
+// Places the weekly, dated and recurring windows between origin and end, less
+// the busy periods, as sorted and non overlapping intervals from origin
+func (a Availability) between(origin, end time.Time) []interval {
+	location := a.location()
+	limit := end.Sub(origin)
+	intervals := []interval{}
+	add := func(day model.Date, start, end time.Duration) {
+		from := wallClock(day.Year, day.Month, day.Day, start, location).Sub(origin)
+		to := wallClock(day.Year, day.Month, day.Day, end, location).Sub(origin)
+		if from < 0 {
+			from = 0
+		}
+		if to > limit {
+			to = limit
+		}
+		if from < to {
+			intervals = append(intervals, interval{from, to})
+		}
+	}
+	// Every day of the person's zone overlapping the period
+	first, last := model.DateOf(origin.In(location)), model.DateOf(end.In(location))
+	for _, window := range a.Recurring {
+		for _, day := range window.Rule.Between(window.First.In(time.UTC), first.In(time.UTC), last.In(time.UTC)) {
+			add(model.DateOf(day), window.Start, window.End)
+		}
+	}
+	for day := first; !last.Before(day); day = day.AddDays(1) {
+		for _, window := range a.Windows {
+			for _, weekday := range window.Weekdays {
+				if weekday == day.Weekday() {
+					add(day, window.Start, window.End)
+				}
+			}
+		}
+		for _, window := range a.Dates {
+			if window.Date == day {
+				add(day, window.Start, window.End)
+			}
+		}
+	}
+	return subtract(union(intervals), a.busy(origin, end))
+}
+
+// Places the busy periods between origin and end as sorted and non
+// overlapping intervals from origin
+func (a Availability) busy(origin, end time.Time) []interval {
+	limit := end.Sub(origin)
+	busy := []interval{}
+	for _, period := range a.Busy {
+		from, to := period.Start.Sub(origin), period.End.Sub(origin)
+		if from < 0 {
+			from = 0
+		}
+		if to > limit {
+			to = limit
+		}
+		if from < to {
+			busy = append(busy, interval{from, to})
+		}
+	}
+	return union(busy)
+}
+
+func (a Availability) location() *time.Location {
+	if a.Location == nil {
+		return time.UTC
+	}
+	return a.Location
+}
+
+// Absolute time of a wall clock offset on a day, for offsets of up to 24
+// hours, which time.Date normalizes to the next midnight
+func wallClock(year int, month time.Month, day int, offset time.Duration, location *time.Location) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, int(offset), location)
+}
+
+// Brings an interval inside the week, splitting it when it crosses either
+// end of the week
+func wrap(from, to time.Duration) []interval {
+	for from < 0 {
+		from, to = from+Week, to+Week
+	}
+	for from >= Week {
+		from, to = from-Week, to-Week
+	}
+	if to <= Week {
+		return []interval{{from, to}}
+	}
+	return []interval{{from, Week}, {0, to - Week}}
+}
+
+// Converts intervals of the UTC week starting at start to windows in the
+// wall clock of location, split at its midnights
+func windows(intervals []interval, start time.Time, location *time.Location) []Window {
+	if location == nil {
+		location = time.UTC
+	}
+	var byWeekday [7][]interval
+	for _, i := range intervals {
+		split(i, start, location, func(day time.Time, start, end time.Duration) {
+			byWeekday[day.Weekday()] = append(byWeekday[day.Weekday()], interval{start, end})
+		})
+	}
+
+	windows := []Window{}
+	for weekday, intervals := range byWeekday {
+		for _, i := range union(intervals) {
+			windows = append(windows, Window{
+				Start:    i.start,
+				End:      i.end,
+				Weekdays: []time.Weekday{time.Weekday(weekday)},
+			})
+		}
+	}
+	return windows
+}
+
+// Splits an interval from origin at the midnights of location, calling add
+// with the local start of each piece and its wall clock offsets
+func split(i interval, origin time.Time, location *time.Location, add func(day time.Time, start, end time.Duration)) {
+	from, to := origin.Add(i.start).In(location), origin.Add(i.end).In(location)
+	for from.Before(to) {
+		midnight := time.Date(from.Year(), from.Month(), from.Day()+1, 0, 0, 0, 0, location)
+		end := 24 * time.Hour
+		if to.Before(midnight) {
+			end = sinceMidnight(to)
+		}
+		add(from, sinceMidnight(from), end)
+		from = midnight
+	}
+}
+
+// Wall clock offset of t from its midnight
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// Merges overlapping and touching intervals
+func union(intervals []interval) []interval {
+	if len(intervals) == 0 {
+		return intervals
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start < intervals[j].start
+	})
+	merged := []interval{intervals[0]}
+	for _, i := range intervals[1:] {
+		last := &merged[len(merged)-1]
+		if i.start <= last.end {
+			if i.end > last.end {
+				last.end = i.end
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// Both lists must be sorted and non overlapping, as union returns them
+func intersect(a, b []interval) []interval {
+	common := []interval{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := a[i].start, a[i].end
+		if b[j].start > start {
+			start = b[j].start
+		}
+		if b[j].end < end {
+			end = b[j].end
+		}
+		if start < end {
+			common = append(common, interval{start, end})
+		}
+		if a[i].end < b[j].end {
+			i++
+		} else {
+			j++
+		}
+	}
+	return common
+}
+
+// The parts of a not in b, both lists being sorted and non overlapping
+func subtract(a, b []interval) []interval {
+	remaining := []interval{}
+	j := 0
+	for _, i := range a {
+		for j < len(b) && b[j].end <= i.start {
+			j++
+		}
+		start := i.start
+		for k := j; k < len(b) && b[k].start < i.end; k++ {
+			if b[k].start > start {
+				remaining = append(remaining, interval{start, b[k].start})
+			}
+			if b[k].end > start {
+				start = b[k].end
+			}
+		}
+		if start < i.end {
+			remaining = append(remaining, interval{start, i.end})
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,576 @@
+package scheduling
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/rrule"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+// Offsets written as hours and minutes, such as 9h30m
+func at(hours, minutes int) time.Duration {
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute
+}
+
+func window(start, end time.Duration, weekdays ...time.Weekday) Window {
+	return Window{Start: start, End: end, Weekdays: weekdays}
+}
+
+func date(year int, month time.Month, day int) model.Date {
+	return model.Date{Year: year, Month: month, Day: day}
+}
+
+func TestUnion(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []interval
+		want      []interval
+	}{
+		{"empty", []interval{}, []interval{}},
+		{"disjoint unsorted", []interval{{at(3, 0), at(4, 0)}, {at(1, 0), at(2, 0)}}, []interval{{at(1, 0), at(2, 0)}, {at(3, 0), at(4, 0)}}},
+		{"overlapping", []interval{{at(1, 0), at(3, 0)}, {at(2, 0), at(4, 0)}}, []interval{{at(1, 0), at(4, 0)}}},
+		{"touching", []interval{{at(1, 0), at(2, 0)}, {at(2, 0), at(3, 0)}}, []interval{{at(1, 0), at(3, 0)}}},
+		{"contained", []interval{{at(1, 0), at(5, 0)}, {at(2, 0), at(3, 0)}}, []interval{{at(1, 0), at(5, 0)}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := union(test.intervals); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("union = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []interval
+		want []interval
+	}{
+		{"empty", []interval{{at(1, 0), at(2, 0)}}, []interval{}, []interval{}},
+		{"overlapping", []interval{{at(1, 0), at(3, 0)}}, []interval{{at(2, 0), at(4, 0)}}, []interval{{at(2, 0), at(3, 0)}}},
+		{"touching", []interval{{at(1, 0), at(2, 0)}}, []interval{{at(2, 0), at(3, 0)}}, []interval{}},
+		{"several", []interval{{at(1, 0), at(5, 0)}}, []interval{{at(0, 0), at(2, 0)}, {at(3, 0), at(4, 0)}, {at(4, 30), at(6, 0)}},
+			[]interval{{at(1, 0), at(2, 0)}, {at(3, 0), at(4, 0)}, {at(4, 30), at(5, 0)}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := intersect(test.a, test.b); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("intersect = %v, want %v", got, test.want)
+			}
+			if got := intersect(test.b, test.a); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("intersect reversed = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestSubtract(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []interval
+		want []interval
+	}{
+		{"nothing", []interval{{at(1, 0), at(2, 0)}}, []interval{}, []interval{{at(1, 0), at(2, 0)}}},
+		{"all", []interval{{at(1, 0), at(2, 0)}}, []interval{{at(0, 0), at(3, 0)}}, []interval{}},
+		{"middle", []interval{{at(1, 0), at(4, 0)}}, []interval{{at(2, 0), at(3, 0)}}, []interval{{at(1, 0), at(2, 0)}, {at(3, 0), at(4, 0)}}},
+		{"ends", []interval{{at(1, 0), at(4, 0)}}, []interval{{at(0, 0), at(2, 0)}, {at(3, 0), at(5, 0)}}, []interval{{at(2, 0), at(3, 0)}}},
+		{"spanning two", []interval{{at(1, 0), at(2, 0)}, {at(3, 0), at(4, 0)}}, []interval{{at(1, 30), at(3, 30)}},
+			[]interval{{at(1, 0), at(1, 30)}, {at(3, 30), at(4, 0)}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := subtract(test.a, test.b); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("subtract = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	lisbon, berlin := mustLoad(t, "Europe/Lisbon"), mustLoad(t, "Europe/Berlin")
+	tokyo, newYork := mustLoad(t, "Asia/Tokyo"), mustLoad(t, "America/New_York")
+	november := time.Date(2026, time.November, 4, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name         string
+		reference    time.Time
+		location     *time.Location
+		candidate    Availability
+		interviewers []Availability
+		want         []Window
+	}{
+		{
+			name:         "same zone",
+			reference:    november,
+			location:     time.UTC,
+			candidate:    Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Monday, time.Wednesday)}},
+			interviewers: []Availability{{Windows: []Window{window(at(10, 0), at(14, 0), time.Wednesday)}}},
+			want:         []Window{window(at(10, 0), at(12, 0), time.Wednesday)},
+		},
+		{
+			name:         "no interviewer",
+			reference:    november,
+			location:     time.UTC,
+			candidate:    Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Friday)}},
+			interviewers: []Availability{},
+			want:         []Window{window(at(9, 0), at(12, 0), time.Friday)},
+		},
+		{
+			name:         "different zones",
+			reference:    november,
+			location:     berlin,
+			candidate:    Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Wednesday)}, Location: lisbon},
+			interviewers: []Availability{{Windows: []Window{window(at(10, 0), at(14, 0), time.Wednesday)}, Location: berlin}},
+			want:         []Window{window(at(10, 0), at(13, 0), time.Wednesday)},
+		},
+		{
+			name:      "crossing midnight once converted",
+			reference: november,
+			location:  time.UTC,
+			candidate: Availability{Windows: []Window{window(at(8, 0), at(10, 0), time.Monday)}, Location: tokyo},
+			want: []Window{
+				window(at(23, 0), at(24, 0), time.Sunday),
+				window(at(0, 0), at(1, 0), time.Monday),
+			},
+		},
+		{
+			// New York is on summer time, Berlin not yet
+			name:         "daylight saving time in one zone",
+			reference:    time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC),
+			location:     time.UTC,
+			candidate:    Availability{Windows: []Window{window(at(9, 0), at(17, 0), time.Tuesday)}, Location: newYork},
+			interviewers: []Availability{{Windows: []Window{window(at(9, 0), at(17, 0), time.Tuesday)}, Location: berlin}},
+			want:         []Window{window(at(13, 0), at(16, 0), time.Tuesday)},
+		},
+		{
+			name:         "nothing in common",
+			reference:    november,
+			location:     time.UTC,
+			candidate:    Availability{Windows: []Window{window(at(9, 0), at(10, 0), time.Monday)}},
+			interviewers: []Availability{{Windows: []Window{window(at(10, 0), at(11, 0), time.Monday)}}},
+			want:         []Window{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Match(test.reference, test.location, test.candidate, test.interviewers)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Match = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchBusy(t *testing.T) {
+	wednesday := []Window{window(at(9, 0), at(12, 0), time.Wednesday)}
+	reference := time.Date(2026, time.November, 4, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		busy []Period
+		want []Window
+	}{
+		{
+			name: "free",
+			want: []Window{window(at(9, 0), at(12, 0), time.Wednesday)},
+		},
+		{
+			name: "busy on the next occurrence",
+			busy: []Period{{
+				Start: time.Date(2026, time.November, 4, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, time.November, 4, 11, 0, 0, 0, time.UTC),
+			}},
+			want: []Window{
+				window(at(9, 0), at(10, 0), time.Wednesday),
+				window(at(11, 0), at(12, 0), time.Wednesday),
+			},
+		},
+		{
+			name: "busy after the next seven days",
+			busy: []Period{{
+				Start: time.Date(2026, time.November, 11, 10, 0, 0, 0, time.UTC),
+				End:   time.Date(2026, time.November, 11, 11, 0, 0, 0, time.UTC),
+			}},
+			want: []Window{window(at(9, 0), at(12, 0), time.Wednesday)},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidate := Availability{Windows: wednesday}
+			interviewer := Availability{Windows: wednesday, Busy: test.busy}
+			got := Match(reference, time.UTC, candidate, []Availability{interviewer})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Match = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMatchDates(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	mondays, err := rrule.Parse("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name         string
+		from, to     model.Date
+		location     *time.Location
+		candidate    Availability
+		interviewers []Availability
+		want         []DatedWindow
+	}{
+		{
+			name:      "weekly windows on every weekday",
+			from:      date(2026, time.November, 2),
+			to:        date(2026, time.November, 15),
+			location:  time.UTC,
+			candidate: Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Wednesday)}},
+			want: []DatedWindow{
+				{date(2026, time.November, 4), at(9, 0), at(12, 0)},
+				{date(2026, time.November, 11), at(9, 0), at(12, 0)},
+			},
+		},
+		{
+			name:     "dated and recurring windows",
+			from:     date(2026, time.November, 2),
+			to:       date(2026, time.November, 30),
+			location: time.UTC,
+			candidate: Availability{
+				Dates:     []DatedWindow{{date(2026, time.November, 5), at(14, 0), at(15, 0)}},
+				Recurring: []RecurringWindow{{Rule: mondays, First: date(2026, time.November, 2), Start: at(9, 0), End: at(10, 0)}},
+			},
+			want: []DatedWindow{
+				{date(2026, time.November, 2), at(9, 0), at(10, 0)},
+				{date(2026, time.November, 5), at(14, 0), at(15, 0)},
+				{date(2026, time.November, 16), at(9, 0), at(10, 0)},
+				{date(2026, time.November, 30), at(9, 0), at(10, 0)},
+			},
+		},
+		{
+			name:      "busy periods",
+			from:      date(2026, time.November, 4),
+			to:        date(2026, time.November, 4),
+			location:  time.UTC,
+			candidate: Availability{Windows: []Window{window(at(9, 0), at(17, 0), time.Wednesday)}},
+			interviewers: []Availability{{
+				Windows: []Window{window(at(8, 0), at(18, 0), time.Wednesday)},
+				Busy: []Period{{
+					Start: time.Date(2026, time.November, 4, 12, 0, 0, 0, time.UTC),
+					End:   time.Date(2026, time.November, 4, 13, 30, 0, 0, time.UTC),
+				}},
+			}},
+			want: []DatedWindow{
+				{date(2026, time.November, 4), at(9, 0), at(12, 0)},
+				{date(2026, time.November, 4), at(13, 30), at(17, 0)},
+			},
+		},
+		{
+			// Berlin moves to summer time on March 29th
+			name:      "offsets of each day",
+			from:      date(2026, time.March, 22),
+			to:        date(2026, time.March, 29),
+			location:  time.UTC,
+			candidate: Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Sunday)}, Location: berlin},
+			want: []DatedWindow{
+				{date(2026, time.March, 22), at(8, 0), at(11, 0)},
+				{date(2026, time.March, 29), at(7, 0), at(10, 0)},
+			},
+		},
+		{
+			name:      "split at the midnights of location",
+			from:      date(2026, time.November, 4),
+			to:        date(2026, time.November, 5),
+			location:  berlin,
+			candidate: Availability{Windows: []Window{window(at(22, 0), at(24, 0), time.Wednesday)}},
+			want: []DatedWindow{
+				{date(2026, time.November, 4), at(23, 0), at(24, 0)},
+				{date(2026, time.November, 5), at(0, 0), at(1, 0)},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := MatchDates(test.from, test.to, test.location, test.candidate, test.interviewers)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MatchDates = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		name           string
+		windows        []Window
+		duration, step time.Duration
+		want           []Window
+	}{
+		{
+			name:     "whole duration fits",
+			windows:  []Window{window(at(10, 0), at(12, 0), time.Monday)},
+			duration: 45 * time.Minute,
+			step:     15 * time.Minute,
+			want: []Window{
+				window(at(10, 0), at(10, 45), time.Monday),
+				window(at(10, 15), at(11, 0), time.Monday),
+				window(at(10, 30), at(11, 15), time.Monday),
+				window(at(10, 45), at(11, 30), time.Monday),
+				window(at(11, 0), at(11, 45), time.Monday),
+				window(at(11, 15), at(12, 0), time.Monday),
+			},
+		},
+		{
+			name:     "exactly the window",
+			windows:  []Window{window(at(10, 0), at(11, 30), time.Monday)},
+			duration: 90 * time.Minute,
+			step:     15 * time.Minute,
+			want:     []Window{window(at(10, 0), at(11, 30), time.Monday)},
+		},
+		{
+			name:     "starts on the step from midnight",
+			windows:  []Window{window(at(10, 5), at(11, 30), time.Monday, time.Tuesday)},
+			duration: time.Hour,
+			step:     30 * time.Minute,
+			want: []Window{
+				window(at(10, 30), at(11, 30), time.Monday),
+				window(at(10, 30), at(11, 30), time.Tuesday),
+			},
+		},
+		{
+			name:     "too short",
+			windows:  []Window{window(at(10, 0), at(10, 30), time.Monday)},
+			duration: time.Hour,
+			step:     15 * time.Minute,
+			want:     []Window{},
+		},
+		{
+			name:     "no step",
+			windows:  []Window{window(at(10, 0), at(12, 0), time.Monday)},
+			duration: time.Hour,
+			want:     []Window{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Fit(test.windows, test.duration, test.step); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Fit = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFitDates(t *testing.T) {
+	day := date(2026, time.November, 4)
+	got := FitDates([]DatedWindow{{day, at(10, 0), at(11, 30)}, {day, at(14, 10), at(15, 0)}}, time.UTC, 30*time.Minute, 15*time.Minute)
+	want := []DatedWindow{
+		{day, at(10, 0), at(10, 30)},
+		{day, at(10, 15), at(10, 45)},
+		{day, at(10, 30), at(11, 0)},
+		{day, at(10, 45), at(11, 15)},
+		{day, at(11, 0), at(11, 30)},
+		{day, at(14, 15), at(14, 45)},
+		{day, at(14, 30), at(15, 0)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("FitDates = %v, want %v", got, want)
+	}
+}
+
+func TestFitDatesDaylightSavingTime(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	tests := []struct {
+		name   string
+		date   model.Date
+		starts []string
+	}{
+		{
+			// 02:00 CET jumps to 03:00 CEST
+			name:   "spring forward",
+			date:   date(2026, time.March, 29),
+			starts: []string{"00:00", "01:00", "03:00", "04:00", "05:00"},
+		},
+		{
+			// 03:00 CEST falls back to 02:00 CET, 01:00 CEST ending at a
+			// 02:00 that reads back as CET
+			name:   "fall back",
+			date:   date(2026, time.October, 25),
+			starts: []string{"00:00", "02:00", "03:00", "04:00", "05:00"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			windows := []DatedWindow{{Date: test.date, Start: 0, End: 6 * time.Hour}}
+			interviews := FitDates(windows, berlin, time.Hour, time.Hour)
+			starts := []string{}
+			for _, interview := range interviews {
+				period := interview.Period(berlin)
+				if period.End.Sub(period.Start) != time.Hour {
+					t.Errorf("%v lasts %v, want 1h", interview, period.End.Sub(period.Start))
+				}
+				starts = append(starts, period.Start.Format("15:04"))
+			}
+			if !reflect.DeepEqual(starts, test.starts) {
+				t.Errorf("starts = %v, want %v", starts, test.starts)
+			}
+		})
+	}
+}
+
+func TestMatchLoop(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	day, springForward := date(2026, time.November, 4), date(2026, time.March, 29)
+	// Free from start to end on both days, in Berlin
+	free := func(start, end time.Duration) Availability {
+		return Availability{Dates: []DatedWindow{{day, start, end}, {springForward, start, end}}, Location: berlin}
+	}
+	pooled := func(id int, start, end time.Duration) Interviewer {
+		return Interviewer{Id: id, Availability: free(start, end)}
+	}
+	round := func(duration, pause time.Duration, pool ...Interviewer) Round {
+		return Round{Pool: pool, Duration: duration, Break: pause}
+	}
+	tests := []struct {
+		name   string
+		day    model.Date
+		step   time.Duration
+		rounds []Round
+		// An itinerary per string, each round as interviewer start-end
+		want []string
+	}{
+		{
+			name:   "round order",
+			day:    day,
+			step:   time.Hour,
+			rounds: []Round{round(time.Hour, 0, pooled(1, at(9, 0), at(13, 0))), round(time.Hour, 0, pooled(2, at(9, 0), at(13, 0)))},
+			want:   []string{"1 09:00-10:00, 2 10:00-11:00", "1 10:00-11:00, 2 11:00-12:00", "1 11:00-12:00, 2 12:00-13:00"},
+		},
+		{
+			name:   "later round only free before the first one",
+			day:    day,
+			step:   time.Hour,
+			rounds: []Round{round(time.Hour, 0, pooled(1, at(11, 0), at(13, 0))), round(time.Hour, 0, pooled(2, at(9, 0), at(11, 0)))},
+			want:   []string{},
+		},
+		{
+			name:   "breaks",
+			day:    day,
+			step:   30 * time.Minute,
+			rounds: []Round{round(time.Hour, 0, pooled(1, at(9, 0), at(13, 0))), round(time.Hour, 30*time.Minute, pooled(2, at(9, 0), at(13, 0)))},
+			want:   []string{"1 09:00-10:00, 2 10:30-11:30", "1 09:30-10:30, 2 11:00-12:00", "1 10:00-11:00, 2 11:30-12:30", "1 10:30-11:30, 2 12:00-13:00"},
+		},
+		{
+			name:   "one round per interviewer",
+			day:    day,
+			step:   time.Hour,
+			rounds: []Round{round(time.Hour, 0, pooled(1, at(9, 0), at(13, 0))), round(time.Hour, 0, pooled(1, at(9, 0), at(13, 0)), pooled(2, at(11, 0), at(13, 0)))},
+			want:   []string{"1 09:00-10:00, 2 11:00-12:00", "1 10:00-11:00, 2 11:00-12:00", "1 11:00-12:00, 2 12:00-13:00"},
+		},
+		{
+			// Interviewer 2, the earliest for the second round, is the only
+			// one for the third
+			name: "backtracking",
+			day:  day,
+			step: time.Hour,
+			rounds: []Round{
+				round(time.Hour, 0, pooled(1, at(9, 0), at(10, 0))),
+				round(time.Hour, 0, pooled(2, at(9, 0), at(13, 0)), pooled(3, at(9, 0), at(13, 0))),
+				round(time.Hour, 0, pooled(2, at(9, 0), at(13, 0))),
+			},
+			want: []string{"1 09:00-10:00, 3 10:00-11:00, 2 11:00-12:00"},
+		},
+		{
+			// 02:00 CET jumps to 03:00 CEST, the rounds still last an hour
+			name:   "spring forward",
+			day:    springForward,
+			step:   time.Hour,
+			rounds: []Round{round(time.Hour, 0, pooled(1, 0, at(5, 0))), round(time.Hour, 0, pooled(2, 0, at(5, 0)))},
+			want:   []string{"1 00:00-01:00, 2 01:00-03:00", "1 01:00-03:00, 2 03:00-04:00", "1 03:00-04:00, 2 04:00-05:00"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidate := free(0, 24*time.Hour)
+			got := []string{}
+			for _, itinerary := range MatchLoop(test.day, test.day, berlin, test.step, candidate, nil, test.rounds) {
+				rounds := []string{}
+				for i, assignment := range itinerary {
+					if length := assignment.End.Sub(assignment.Start); length != test.rounds[i].Duration {
+						t.Errorf("round %d lasts %v, want %v", i+1, length, test.rounds[i].Duration)
+					}
+					start, end := assignment.Start.In(berlin), assignment.End.In(berlin)
+					rounds = append(rounds, fmt.Sprintf("%d %s-%s", assignment.Interviewer, start.Format("15:04"), end.Format("15:04")))
+				}
+				got = append(got, strings.Join(rounds, ", "))
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("MatchLoop = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestFreeBusy(t *testing.T) {
+	day := date(2026, time.November, 4)
+	clock := func(hours, minutes int) time.Time {
+		return time.Date(2026, time.November, 4, hours, minutes, 0, 0, time.UTC)
+	}
+	person := Availability{
+		Dates: []DatedWindow{{day, at(9, 0), at(12, 0)}},
+		// Overlapping, and out of the window and of the period
+		Busy: []Period{{clock(10, 0), clock(10, 30)}, {clock(10, 15), clock(11, 0)}, {clock(12, 30), clock(14, 0)}},
+	}
+	got := person.FreeBusy(Period{clock(8, 0), clock(13, 0)})
+	want := FreeBusy{
+		Free:        []Period{{clock(9, 0), clock(10, 0)}, {clock(11, 0), clock(12, 0)}},
+		Busy:        []Period{{clock(10, 0), clock(11, 0)}, {clock(12, 30), clock(13, 0)}},
+		Unavailable: []Period{{clock(8, 0), clock(9, 0)}, {clock(12, 0), clock(12, 30)}},
+	}
+	for _, periods := range []struct {
+		name      string
+		got, want []Period
+	}{{"free", got.Free, want.Free}, {"busy", got.Busy, want.Busy}, {"unavailable", got.Unavailable, want.Unavailable}} {
+		if len(periods.got) != len(periods.want) {
+			t.Errorf("%s = %v, want %v", periods.name, periods.got, periods.want)
+			continue
+		}
+		for i := range periods.got {
+			if !periods.got[i].Start.Equal(periods.want[i].Start) || !periods.got[i].End.Equal(periods.want[i].End) {
+				t.Errorf("%s = %v, want %v", periods.name, periods.got, periods.want)
+				break
+			}
+		}
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	candidate := Availability{Windows: []Window{window(at(9, 0), at(12, 0), time.Wednesday)}}
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"inside", time.Date(2026, time.November, 4, 9, 0, 0, 0, time.UTC), time.Date(2026, time.November, 4, 10, 0, 0, 0, time.UTC), true},
+		{"past the end", time.Date(2026, time.November, 4, 11, 30, 0, 0, time.UTC), time.Date(2026, time.November, 4, 12, 30, 0, 0, time.UTC), false},
+		{"other day", time.Date(2026, time.November, 5, 9, 0, 0, 0, time.UTC), time.Date(2026, time.November, 5, 10, 0, 0, 0, time.UTC), false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Available(Period{test.start, test.end}, candidate, nil); got != test.want {
+				t.Errorf("Available = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
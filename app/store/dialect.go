@@ -0,0 +1,128 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect holds the SQL that differs between the supported databases.
+type Dialect interface {
+	Name() string
+	// Expression for the current date and time
+	Now() string
+	// Rewrites the ? placeholders used by the store queries
+	Rebind(query string) string
+	// Whether inserts read the new id through RETURNING instead of
+	// LastInsertId
+	Returning() bool
+	// Expression reading a time column back as "15:04:05"
+	Time(column string) string
+	// Suffix of the SELECT statements locking the rows they read until the
+	// end of the transaction
+	ForUpdate() string
+}
+
+func NewDialect(driver string) (Dialect, error) {
+	switch driver {
+	case "mysql":
+		return MySQLDialect{}, nil
+	case "sqlite3":
+		return SQLiteDialect{}, nil
+	case "postgres":
+		return PostgresDialect{}, nil
+	}
+	return nil, fmt.Errorf("store: unsupported driver %q", driver)
+}
+
+type MySQLDialect struct{}
+
+func (MySQLDialect) Name() string {
+	return "mysql"
+}
+
+func (MySQLDialect) Now() string {
+	return "NOW()"
+}
+
+func (MySQLDialect) Rebind(query string) string {
+	return query
+}
+
+func (MySQLDialect) Returning() bool {
+	return false
+}
+
+func (MySQLDialect) Time(column string) string {
+	return column
+}
+
+func (MySQLDialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
+
+type SQLiteDialect struct{}
+
+func (SQLiteDialect) Name() string {
+	return "sqlite3"
+}
+
+func (SQLiteDialect) Now() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (SQLiteDialect) Rebind(query string) string {
+	return query
+}
+
+func (SQLiteDialect) Returning() bool {
+	return false
+}
+
+func (SQLiteDialect) Time(column string) string {
+	return column
+}
+
+// SQLite has no row locks, its transactions take the database write lock
+// when they begin, thanks to the _txlock=immediate connection option
+func (SQLiteDialect) ForUpdate() string {
+	return ""
+}
+
+type PostgresDialect struct{}
+
+func (PostgresDialect) Name() string {
+	return "postgres"
+}
+
+func (PostgresDialect) Now() string {
+	return "NOW()"
+}
+
+// Numbers the placeholders as $1, $2, ...
+func (PostgresDialect) Rebind(query string) string {
+	var rebound strings.Builder
+	n := 0
+	for _, r := range query {
+		if r != '?' {
+			rebound.WriteRune(r)
+			continue
+		}
+		n++
+		rebound.WriteString("$" + strconv.Itoa(n))
+	}
+	return rebound.String()
+}
+
+func (PostgresDialect) Returning() bool {
+	return true
+}
+
+// The driver reads time columns as time.Time, format them as MySQL does
+func (PostgresDialect) Time(column string) string {
+	return "to_char(" + column + ", 'HH24:MI:SS')"
+}
+
+func (PostgresDialect) ForUpdate() string {
+	return " FOR UPDATE"
+}
@@ -0,0 +1,774 @@
+package store
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+)
+
+// Memory keeps every table in process memory. It hands out ids, orders
+// results and reports missing records exactly like the SQL store, so it
+// can stand in for it during development and tests.
+type Memory struct {
+	mu sync.RWMutex
+
+	candidates   map[int]model.Candidate
+	interviewers map[int]model.Interviewer
+	slots        map[int]memorySlot
+	exceptions   map[int]memoryException
+	bookings     map[int]model.Booking
+	// booking_events rows by booking id, oldest first
+	events map[int][]model.BookingEvent
+	holds  map[int]model.Hold
+	// calendar_token column of the candidates and interviewers tables
+	tokens map[memoryPerson]string
+	// caldav_password column of the interviewers table
+	davPasswords map[int]string
+
+	// Last auto increment value handed out per table
+	lastCandidateId   int
+	lastInterviewerId int
+	lastSlotId        int
+	lastExceptionId   int
+	lastBookingId     int
+	lastHoldId        int
+}
+
+// Row of the slots table joined with its slots_weekdays rows
+type memorySlot struct {
+	owner    Owner
+	slot     model.Slot
+	weekdays []time.Weekday
+}
+
+type memoryPerson struct {
+	owner Owner
+	id    int
+}
+
+type memoryException struct {
+	owner     Owner
+	exception model.Exception
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		candidates:   map[int]model.Candidate{},
+		interviewers: map[int]model.Interviewer{},
+		slots:        map[int]memorySlot{},
+		exceptions:   map[int]memoryException{},
+		bookings:     map[int]model.Booking{},
+		events:       map[int][]model.BookingEvent{},
+		holds:        map[int]model.Hold{},
+		tokens:       map[memoryPerson]string{},
+		davPasswords: map[int]string{},
+	}
+}
+
+/* CANDIDATES */
+func (s *Memory) AddCandidate(candidate *model.Candidate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastCandidateId++
+	candidate.Id = s.lastCandidateId
+	s.candidates[candidate.Id] = *candidate
+	return nil
+}
+
+func (s *Memory) GetCandidate(id int) (model.Candidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	candidate, ok := s.candidates[id]
+	if !ok {
+		return model.Candidate{}, ErrNotFound
+	}
+	return candidate, nil
+}
+
+func (s *Memory) GetAllCandidates() ([]model.Candidate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := []int{}
+	for id := range s.candidates {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	candidates := []model.Candidate{}
+	for _, id := range ids {
+		candidates = append(candidates, s.candidates[id])
+	}
+	return candidates, nil
+}
+
+func (s *Memory) UpdateCandidate(candidate model.Candidate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.candidates[candidate.Id]; ok {
+		s.candidates[candidate.Id] = candidate
+	}
+	return nil
+}
+
+func (s *Memory) DeleteCandidate(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.candidates, id)
+	s.cascade(CandidateOwner, id)
+	return nil
+}
+
+/* CANDIDATES */
+/* INTERVIEWERS */
+func (s *Memory) AddInterviewer(interviewer *model.Interviewer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastInterviewerId++
+	interviewer.Id = s.lastInterviewerId
+	s.interviewers[interviewer.Id] = *interviewer
+	return nil
+}
+
+func (s *Memory) GetInterviewer(id int) (model.Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	interviewer, ok := s.interviewers[id]
+	if !ok {
+		return model.Interviewer{}, ErrNotFound
+	}
+	return interviewer, nil
+}
+
+func (s *Memory) GetAllInterviewers() ([]model.Interviewer, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := []int{}
+	for id := range s.interviewers {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	interviewers := []model.Interviewer{}
+	for _, id := range ids {
+		interviewers = append(interviewers, s.interviewers[id])
+	}
+	return interviewers, nil
+}
+
+func (s *Memory) UpdateInterviewer(interviewer model.Interviewer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.interviewers[interviewer.Id]; ok {
+		s.interviewers[interviewer.Id] = interviewer
+	}
+	return nil
+}
+
+func (s *Memory) DeleteInterviewer(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.interviewers, id)
+	s.cascade(InterviewerOwner, id)
+	return nil
+}
+
+/* INTERVIEWERS */
+/* SLOTS */
+func (s *Memory) AddSlot(owner Owner, slot *model.Slot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(owner, slot.PersonId) {
+		return ErrNotFound
+	}
+	s.addSlot(owner, slot)
+	return nil
+}
+
+func (s *Memory) addSlot(owner Owner, slot *model.Slot) {
+	s.lastSlotId++
+	slot.Id = s.lastSlotId
+	s.slots[slot.Id] = memorySlot{
+		owner:    owner,
+		slot:     model.Slot{Id: slot.Id, PersonId: slot.PersonId, Date: copyDate(slot.Date), InitialTime: slot.InitialTime, FinalTime: slot.FinalTime, RRule: slot.RRule, Uid: slot.Uid, Resource: slot.Resource},
+		weekdays: copyWeekdays(slot.Weekdays),
+	}
+}
+
+func (s *Memory) GetSlots(owner Owner, personId int) ([]model.Slot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := []int{}
+	for id, row := range s.slots {
+		if row.owner == owner && row.slot.PersonId == personId {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	slots := []model.Slot{}
+	for _, id := range ids {
+		row := s.slots[id]
+		slot := row.slot
+		slot.Date = copyDate(row.slot.Date)
+		slot.Weekdays = copyWeekdays(row.weekdays)
+		slots = append(slots, slot)
+	}
+	return slots, nil
+}
+
+// Like the SQL store, the owner of a slot never changes on update
+func (s *Memory) UpdateSlot(slot model.Slot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateSlot(slot)
+	return nil
+}
+
+func (s *Memory) updateSlot(slot model.Slot) {
+	row, ok := s.slots[slot.Id]
+	if !ok {
+		return
+	}
+	row.slot.Date = copyDate(slot.Date)
+	row.slot.InitialTime = slot.InitialTime
+	row.slot.FinalTime = slot.FinalTime
+	row.slot.RRule = slot.RRule
+	row.weekdays = copyWeekdays(slot.Weekdays)
+	s.slots[slot.Id] = row
+}
+
+func (s *Memory) DeleteSlot(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.slots, id)
+	return nil
+}
+
+/* SLOTS */
+/* EXCEPTIONS */
+func (s *Memory) AddException(owner Owner, exception *model.Exception) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(owner, exception.PersonId) {
+		return ErrNotFound
+	}
+	s.addException(owner, exception)
+	return nil
+}
+
+func (s *Memory) addException(owner Owner, exception *model.Exception) {
+	s.lastExceptionId++
+	exception.Id = s.lastExceptionId
+	s.exceptions[exception.Id] = memoryException{owner: owner, exception: *exception}
+}
+
+func (s *Memory) GetExceptions(owner Owner, personId int) ([]model.Exception, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ids := []int{}
+	for id, row := range s.exceptions {
+		if row.owner == owner && row.exception.PersonId == personId {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	exceptions := []model.Exception{}
+	for _, id := range ids {
+		exceptions = append(exceptions, s.exceptions[id].exception)
+	}
+	return exceptions, nil
+}
+
+// Like the SQL store, the owner of an exception never changes on update
+func (s *Memory) UpdateException(exception model.Exception) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	row, ok := s.exceptions[exception.Id]
+	if !ok {
+		return nil
+	}
+	exception.PersonId, exception.Uid = row.exception.PersonId, row.exception.Uid
+	row.exception = exception
+	s.exceptions[exception.Id] = row
+	return nil
+}
+
+func (s *Memory) DeleteException(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.exceptions, id)
+	return nil
+}
+
+/* EXCEPTIONS */
+/* BOOKINGS */
+func (s *Memory) AddBooking(booking *model.Booking) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(CandidateOwner, booking.Candidate.Id) {
+		return ErrNotFound
+	}
+	for _, interviewer := range booking.Interviewers {
+		if !s.personExists(InterviewerOwner, interviewer.Id) {
+			return ErrNotFound
+		}
+	}
+	if err := s.checkConflicts(*booking); err != nil {
+		return err
+	}
+	s.lastBookingId++
+	booking.Id = s.lastBookingId
+	s.bookings[booking.Id] = copyBooking(*booking)
+	s.addEvent(*booking, "")
+	return nil
+}
+
+func (s *Memory) GetBooking(id int) (model.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	booking, ok := s.bookings[id]
+	if !ok {
+		return model.Booking{}, ErrNotFound
+	}
+	return copyBooking(booking), nil
+}
+
+func (s *Memory) GetAllBookings(statuses []string) ([]model.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bookings := []model.Booking{}
+	for _, booking := range s.bookings {
+		if len(statuses) == 0 || hasStatus(booking, statuses) {
+			bookings = append(bookings, copyBooking(booking))
+		}
+	}
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (s *Memory) GetBookings(owner Owner, personId int) ([]model.Booking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	bookings := []model.Booking{}
+	for _, booking := range s.bookings {
+		if hasPerson(booking, owner, personId) {
+			bookings = append(bookings, copyBooking(booking))
+		}
+	}
+	sortBookings(bookings)
+	return bookings, nil
+}
+
+func (s *Memory) SetBookingStatus(id int, status string) (model.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking, ok := s.bookings[id]
+	if !ok {
+		return model.Booking{}, ErrNotFound
+	}
+	if err := model.CheckTransition(booking.Status, status); err != nil {
+		return model.Booking{}, err
+	}
+	previous := booking.Status
+	booking.Status = status
+	s.bookings[id] = booking
+	s.addEvent(booking, previous)
+	return copyBooking(booking), nil
+}
+
+func (s *Memory) MoveBooking(id int, start, end time.Time) (model.Booking, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	booking, ok := s.bookings[id]
+	if !ok {
+		return model.Booking{}, ErrNotFound
+	}
+	if err := model.CheckTransition(booking.Status, model.BookingRescheduled); err != nil {
+		return model.Booking{}, err
+	}
+	previous := booking.Status
+	booking.Start, booking.End, booking.Status = start, end, model.BookingRescheduled
+	if err := s.checkConflicts(booking); err != nil {
+		return model.Booking{}, err
+	}
+	s.bookings[id] = booking
+	s.addEvent(booking, previous)
+	return copyBooking(booking), nil
+}
+
+func (s *Memory) GetBookingHistory(id int) ([]model.BookingEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	history := []model.BookingEvent{}
+	return append(history, s.events[id]...), nil
+}
+
+// Returns a *ConflictError with the first other active booking sharing
+// people with the booking and overlapping it
+func (s *Memory) checkConflicts(booking model.Booking) error {
+	conflicts := []model.Booking{}
+	for _, existing := range s.bookings {
+		if existing.Id != booking.Id && existing.Active() && existing.Start.Before(booking.End) && existing.End.After(booking.Start) && sharePeople(existing, booking) {
+			conflicts = append(conflicts, copyBooking(existing))
+		}
+	}
+	if len(conflicts) > 0 {
+		sortBookings(conflicts)
+		return &ConflictError{Booking: conflicts[0]}
+	}
+	return nil
+}
+
+// Timestamps are truncated to seconds like the SQL ones
+func (s *Memory) addEvent(booking model.Booking, previous string) {
+	s.events[booking.Id] = append(s.events[booking.Id], model.BookingEvent{
+		Status:         booking.Status,
+		PreviousStatus: previous,
+		Start:          booking.Start,
+		End:            booking.End,
+		At:             time.Now().UTC().Truncate(time.Second),
+	})
+}
+
+/* BOOKINGS */
+/* HOLDS */
+func (s *Memory) AddHold(hold *model.Hold) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(CandidateOwner, hold.Candidate.Id) {
+		return ErrNotFound
+	}
+	for _, interviewer := range hold.Interviewers {
+		if !s.personExists(InterviewerOwner, interviewer.Id) {
+			return ErrNotFound
+		}
+	}
+	booking := model.Booking{Start: hold.Start, End: hold.End, Candidate: hold.Candidate, Interviewers: hold.Interviewers}
+	if err := s.checkConflicts(booking); err != nil {
+		return err
+	}
+	if err := s.checkHoldConflicts(*hold); err != nil {
+		return err
+	}
+	s.lastHoldId++
+	hold.Id = s.lastHoldId
+	s.holds[hold.Id] = copyHold(*hold)
+	return nil
+}
+
+// Returns a *HoldConflictError with the first active hold of one of the
+// interviewers for another candidate overlapping the hold
+func (s *Memory) checkHoldConflicts(hold model.Hold) error {
+	now := time.Now()
+	conflicts := []model.Hold{}
+	for _, existing := range s.holds {
+		if existing.Candidate.Id != hold.Candidate.Id && !existing.Expired(now) && existing.Start.Before(hold.End) && existing.End.After(hold.Start) && shareInterviewers(existing, hold) {
+			conflicts = append(conflicts, copyHold(existing))
+		}
+	}
+	if len(conflicts) > 0 {
+		sortHolds(conflicts)
+		return &HoldConflictError{Hold: conflicts[0]}
+	}
+	return nil
+}
+
+func (s *Memory) GetHold(id int) (model.Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	hold, ok := s.holds[id]
+	if !ok {
+		return model.Hold{}, ErrNotFound
+	}
+	return copyHold(hold), nil
+}
+
+func (s *Memory) GetAllHolds() ([]model.Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	holds := []model.Hold{}
+	for _, hold := range s.holds {
+		holds = append(holds, copyHold(hold))
+	}
+	sortHolds(holds)
+	return holds, nil
+}
+
+func (s *Memory) GetHolds(interviewerId int) ([]model.Hold, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	holds := []model.Hold{}
+	for _, hold := range s.holds {
+		for _, interviewer := range hold.Interviewers {
+			if interviewer.Id == interviewerId {
+				holds = append(holds, copyHold(hold))
+				break
+			}
+		}
+	}
+	sortHolds(holds)
+	return holds, nil
+}
+
+func (s *Memory) DeleteHold(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.holds, id)
+	return nil
+}
+
+func (s *Memory) DeleteExpiredHolds(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := 0
+	for id, hold := range s.holds {
+		if hold.Expired(now) {
+			delete(s.holds, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+/* HOLDS */
+/* CALENDAR TOKENS */
+func (s *Memory) GetCalendarToken(owner Owner, personId int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.personExists(owner, personId) {
+		return "", ErrNotFound
+	}
+	return s.tokens[memoryPerson{owner, personId}], nil
+}
+
+func (s *Memory) SetCalendarToken(owner Owner, personId int, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(owner, personId) {
+		return ErrNotFound
+	}
+	s.tokens[memoryPerson{owner, personId}] = token
+	return nil
+}
+
+func (s *Memory) GetCalDAVPassword(interviewerId int) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.personExists(InterviewerOwner, interviewerId) {
+		return "", ErrNotFound
+	}
+	return s.davPasswords[interviewerId], nil
+}
+
+func (s *Memory) SetCalDAVPassword(interviewerId int, password string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(InterviewerOwner, interviewerId) {
+		return ErrNotFound
+	}
+	s.davPasswords[interviewerId] = password
+	return nil
+}
+
+/* CALENDAR TOKENS */
+/* CALENDARS */
+// WriteCalendar checks the person and the slots to update before changing
+// anything, so that the write can't stop midway.
+func (s *Memory) WriteCalendar(write CalendarWrite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.personExists(write.Owner, write.PersonId) {
+		return ErrNotFound
+	}
+	updated := updatedSlots(write)
+	for id := range updated {
+		if row, ok := s.slots[id]; !ok || row.owner != write.Owner || row.slot.PersonId != write.PersonId {
+			return ErrNotFound
+		}
+	}
+
+	for id, row := range s.slots {
+		if row.owner == write.Owner && row.slot.PersonId == write.PersonId && !updated[id] && write.deletes(row.slot) {
+			delete(s.slots, id)
+		}
+	}
+	for id, row := range s.exceptions {
+		if row.owner == write.Owner && row.exception.PersonId == write.PersonId && write.replaces(row.exception.Uid) {
+			delete(s.exceptions, id)
+		}
+	}
+	for _, slot := range write.Slots {
+		slot.PersonId = write.PersonId
+		if slot.Id != 0 {
+			s.updateSlot(*slot)
+		} else {
+			s.addSlot(write.Owner, slot)
+		}
+	}
+	for _, exception := range write.Exceptions {
+		exception.PersonId = write.PersonId
+		s.addException(write.Owner, exception)
+	}
+	return nil
+}
+
+/* CALENDARS */
+
+func (s *Memory) personExists(owner Owner, id int) bool {
+	if owner == CandidateOwner {
+		_, ok := s.candidates[id]
+		return ok
+	}
+	_, ok := s.interviewers[id]
+	return ok
+}
+
+// Same as the ON DELETE CASCADE of the slots, exceptions and bookings foreign
+// keys
+func (s *Memory) cascade(owner Owner, personId int) {
+	delete(s.tokens, memoryPerson{owner, personId})
+	if owner == InterviewerOwner {
+		delete(s.davPasswords, personId)
+	}
+	for id, row := range s.slots {
+		if row.owner == owner && row.slot.PersonId == personId {
+			delete(s.slots, id)
+		}
+	}
+	for id, row := range s.exceptions {
+		if row.owner == owner && row.exception.PersonId == personId {
+			delete(s.exceptions, id)
+		}
+	}
+	for id, booking := range s.bookings {
+		if owner == CandidateOwner && booking.Candidate.Id == personId {
+			delete(s.bookings, id)
+			delete(s.events, id)
+			continue
+		}
+		if owner == InterviewerOwner {
+			booking.Interviewers = withoutInterviewer(booking.Interviewers, personId)
+			s.bookings[id] = booking
+		}
+	}
+	for id, hold := range s.holds {
+		if owner == CandidateOwner && hold.Candidate.Id == personId {
+			delete(s.holds, id)
+			continue
+		}
+		if owner == InterviewerOwner {
+			hold.Interviewers = withoutInterviewer(hold.Interviewers, personId)
+			s.holds[id] = hold
+		}
+	}
+}
+
+func withoutInterviewer(interviewers []model.Interviewer, id int) []model.Interviewer {
+	remaining := []model.Interviewer{}
+	for _, interviewer := range interviewers {
+		if interviewer.Id != id {
+			remaining = append(remaining, interviewer)
+		}
+	}
+	return remaining
+}
+
+func copyWeekdays(weekdays []time.Weekday) []time.Weekday {
+	return append([]time.Weekday{}, weekdays...)
+}
+
+func copyDate(date *model.Date) *model.Date {
+	if date == nil {
+		return nil
+	}
+	copied := *date
+	return &copied
+}
+
+// Bookings are stored with the ids of their people only, like in the
+// bookings tables
+func copyBooking(booking model.Booking) model.Booking {
+	booking.Candidate = model.Candidate{Id: booking.Candidate.Id}
+	interviewers := []model.Interviewer{}
+	for _, interviewer := range booking.Interviewers {
+		interviewers = append(interviewers, model.Interviewer{Id: interviewer.Id})
+	}
+	booking.Interviewers = interviewers
+	return booking
+}
+
+// Same order as the SQL store, by start time then id
+func sortBookings(bookings []model.Booking) {
+	sort.Slice(bookings, func(i, j int) bool {
+		if !bookings[i].Start.Equal(bookings[j].Start) {
+			return bookings[i].Start.Before(bookings[j].Start)
+		}
+		return bookings[i].Id < bookings[j].Id
+	})
+}
+
+// Holds are stored like the bookings, without their TTL
+func copyHold(hold model.Hold) model.Hold {
+	hold.Candidate = model.Candidate{Id: hold.Candidate.Id}
+	interviewers := []model.Interviewer{}
+	for _, interviewer := range hold.Interviewers {
+		interviewers = append(interviewers, model.Interviewer{Id: interviewer.Id})
+	}
+	hold.Interviewers = interviewers
+	hold.TTL = ""
+	return hold
+}
+
+func sortHolds(holds []model.Hold) {
+	sort.Slice(holds, func(i, j int) bool {
+		if !holds[i].Start.Equal(holds[j].Start) {
+			return holds[i].Start.Before(holds[j].Start)
+		}
+		return holds[i].Id < holds[j].Id
+	})
+}
+
+func hasStatus(booking model.Booking, statuses []string) bool {
+	for _, status := range statuses {
+		if booking.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPerson(booking model.Booking, owner Owner, personId int) bool {
+	if owner == CandidateOwner {
+		return booking.Candidate.Id == personId
+	}
+	for _, interviewer := range booking.Interviewers {
+		if interviewer.Id == personId {
+			return true
+		}
+	}
+	return false
+}
+
+// Whether the holds have an interviewer in common
+func shareInterviewers(a, b model.Hold) bool {
+	for _, x := range a.Interviewers {
+		for _, y := range b.Interviewers {
+			if x.Id == y.Id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Whether the bookings have the same candidate or an interviewer in common
+func sharePeople(a, b model.Booking) bool {
+	if hasPerson(a, CandidateOwner, b.Candidate.Id) {
+		return true
+	}
+	for _, interviewer := range b.Interviewers {
+		if hasPerson(a, InterviewerOwner, interviewer.Id) {
+			return true
+		}
+	}
+	return false
+}
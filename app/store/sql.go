@@ -0,0 +1,880 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+)
+
+// SQL implements every store on top of the tables created by the
+// migrations package, leaving the database specific bits to its Dialect.
+type SQL struct {
+	DB      *sql.DB
+	Dialect Dialect
+	// Set on the copies handed out by transaction, whose queries run in it
+	tx *sql.Tx
+}
+
+func NewSQL(db *sql.DB, dialect Dialect) *SQL {
+	return &SQL{DB: db, Dialect: dialect}
+}
+
+/* CANDIDATES */
+func (s *SQL) AddCandidate(candidate *model.Candidate) error {
+	id, err := s.insert("INSERT INTO candidates (name, time_zone, created_date) VALUES (?, ?, "+s.Dialect.Now()+");", candidate.Name, candidate.TimeZone)
+	if err != nil {
+		return err
+	}
+	candidate.Id = id
+	return nil
+}
+
+func (s *SQL) GetCandidate(id int) (model.Candidate, error) {
+	candidate := model.Candidate{}
+	query := "SELECT id, name, time_zone FROM candidates WHERE id = ?;"
+	err := s.queryRow(query, id).Scan(&candidate.Id, &candidate.Name, &candidate.TimeZone)
+	return candidate, s.rowError(err)
+}
+
+func (s *SQL) GetAllCandidates() ([]model.Candidate, error) {
+	candidates := []model.Candidate{}
+	rows, err := s.query("SELECT id, name, time_zone FROM candidates ORDER BY id;")
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return candidates, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		candidate := model.Candidate{}
+		if err = rows.Scan(&candidate.Id, &candidate.Name, &candidate.TimeZone); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return candidates, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, rows.Err()
+}
+
+func (s *SQL) UpdateCandidate(candidate model.Candidate) error {
+	return s.exec("UPDATE candidates SET name = ?, time_zone = ? WHERE id = ?;", candidate.Name, candidate.TimeZone, candidate.Id)
+}
+
+func (s *SQL) DeleteCandidate(id int) error {
+	return s.exec("DELETE FROM candidates WHERE id = ?;", id)
+}
+
+/* CANDIDATES */
+/* INTERVIEWERS */
+func (s *SQL) AddInterviewer(interviewer *model.Interviewer) error {
+	id, err := s.insert("INSERT INTO interviewers (name, time_zone, created_date) VALUES (?, ?, "+s.Dialect.Now()+");", interviewer.Name, interviewer.TimeZone)
+	if err != nil {
+		return err
+	}
+	interviewer.Id = id
+	return nil
+}
+
+func (s *SQL) GetInterviewer(id int) (model.Interviewer, error) {
+	interviewer := model.Interviewer{}
+	query := "SELECT id, name, time_zone FROM interviewers WHERE id = ?;"
+	err := s.queryRow(query, id).Scan(&interviewer.Id, &interviewer.Name, &interviewer.TimeZone)
+	return interviewer, s.rowError(err)
+}
+
+func (s *SQL) GetAllInterviewers() ([]model.Interviewer, error) {
+	interviewers := []model.Interviewer{}
+	rows, err := s.query("SELECT id, name, time_zone FROM interviewers ORDER BY id;")
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return interviewers, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		interviewer := model.Interviewer{}
+		if err = rows.Scan(&interviewer.Id, &interviewer.Name, &interviewer.TimeZone); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return interviewers, err
+		}
+		interviewers = append(interviewers, interviewer)
+	}
+	return interviewers, rows.Err()
+}
+
+func (s *SQL) UpdateInterviewer(interviewer model.Interviewer) error {
+	return s.exec("UPDATE interviewers SET name = ?, time_zone = ? WHERE id = ?;", interviewer.Name, interviewer.TimeZone, interviewer.Id)
+}
+
+func (s *SQL) DeleteInterviewer(id int) error {
+	return s.exec("DELETE FROM interviewers WHERE id = ?;", id)
+}
+
+/* INTERVIEWERS */
+/* SLOTS */
+func (s *SQL) AddSlot(owner Owner, slot *model.Slot) error {
+	if err := s.personExists(owner, slot.PersonId); err != nil {
+		return err
+	}
+	query := "INSERT INTO slots (" + owner.Column() + ", date, initial_time, final_time, rrule, uid, resource) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	slotId, err := s.insert(query, slot.PersonId, slot.Date, slot.InitialTime, slot.FinalTime, slot.RRule, slot.Uid, slot.Resource)
+	if err != nil {
+		return err
+	}
+	if err = s.insertWeekdays(slotId, slot.Weekdays); err != nil {
+		return err
+	}
+	slot.Id = slotId
+	return nil
+}
+
+func (s *SQL) GetSlots(owner Owner, personId int) ([]model.Slot, error) {
+	slots := []model.Slot{}
+	query := "SELECT id, date, " + s.Dialect.Time("initial_time") + ", " + s.Dialect.Time("final_time") +
+		", rrule, uid, resource FROM slots WHERE " + owner.Column() + " = ? ORDER BY id"
+	rows, err := s.query(query, personId)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return slots, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		slot := model.Slot{PersonId: personId}
+		if err = rows.Scan(&slot.Id, &slot.Date, &slot.InitialTime, &slot.FinalTime, &slot.RRule, &slot.Uid, &slot.Resource); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return slots, err
+		}
+		slots = append(slots, slot)
+	}
+	if err = rows.Err(); err != nil {
+		return slots, err
+	}
+
+	for i := range slots {
+		slots[i].Weekdays, err = s.weekdays(slots[i].Id)
+		if err != nil {
+			return slots, err
+		}
+	}
+	return slots, nil
+}
+
+func (s *SQL) UpdateSlot(slot model.Slot) error {
+	query := "UPDATE slots SET date = ?, initial_time = ?, final_time = ?, rrule = ? WHERE id = ?"
+	if err := s.exec(query, slot.Date, slot.InitialTime, slot.FinalTime, slot.RRule, slot.Id); err != nil {
+		return err
+	}
+	if err := s.exec("DELETE FROM slots_weekdays WHERE slot_id = ?", slot.Id); err != nil {
+		return err
+	}
+	return s.insertWeekdays(slot.Id, slot.Weekdays)
+}
+
+func (s *SQL) DeleteSlot(id int) error {
+	if err := s.exec("DELETE FROM slots_weekdays WHERE slot_id = ?", id); err != nil {
+		return err
+	}
+	return s.exec("DELETE FROM slots WHERE id = ?", id)
+}
+
+func (s *SQL) personExists(owner Owner, id int) error {
+	var found int
+	query := "SELECT id FROM " + string(owner) + "s WHERE id = ?;"
+	return s.rowError(s.queryRow(query, id).Scan(&found))
+}
+
+func (s *SQL) weekdays(slotId int) ([]time.Weekday, error) {
+	weekdays := []time.Weekday{}
+	rows, err := s.query("SELECT weekday FROM slots_weekdays WHERE slot_id = ? ORDER BY id", slotId)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return weekdays, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var weekday time.Weekday
+		if err = rows.Scan(&weekday); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return weekdays, err
+		}
+		weekdays = append(weekdays, weekday)
+	}
+	return weekdays, rows.Err()
+}
+
+func (s *SQL) insertWeekdays(slotId int, weekdays []time.Weekday) error {
+	for _, weekday := range weekdays {
+		if err := s.exec("INSERT INTO slots_weekdays (slot_id, weekday) VALUES (?, ?);", slotId, weekday); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* SLOTS */
+/* EXCEPTIONS */
+func (s *SQL) AddException(owner Owner, exception *model.Exception) error {
+	if err := s.personExists(owner, exception.PersonId); err != nil {
+		return err
+	}
+	query := "INSERT INTO exceptions (" + owner.Column() + ", from_date, initial_time, to_date, final_time, reason, uid) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	id, err := s.insert(query, exception.PersonId, exception.From, exception.InitialTime, exception.To, exception.FinalTime, exception.Reason, exception.Uid)
+	if err != nil {
+		return err
+	}
+	exception.Id = id
+	return nil
+}
+
+func (s *SQL) GetExceptions(owner Owner, personId int) ([]model.Exception, error) {
+	exceptions := []model.Exception{}
+	query := "SELECT id, from_date, " + s.Dialect.Time("initial_time") + ", to_date, " + s.Dialect.Time("final_time") +
+		", reason, uid FROM exceptions WHERE " + owner.Column() + " = ? ORDER BY id"
+	rows, err := s.query(query, personId)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return exceptions, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		exception := model.Exception{PersonId: personId}
+		err = rows.Scan(&exception.Id, &exception.From, &exception.InitialTime, &exception.To, &exception.FinalTime, &exception.Reason, &exception.Uid)
+		if err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return exceptions, err
+		}
+		exceptions = append(exceptions, exception)
+	}
+	return exceptions, rows.Err()
+}
+
+func (s *SQL) UpdateException(exception model.Exception) error {
+	query := "UPDATE exceptions SET from_date = ?, initial_time = ?, to_date = ?, final_time = ?, reason = ? WHERE id = ?"
+	return s.exec(query, exception.From, exception.InitialTime, exception.To, exception.FinalTime, exception.Reason, exception.Id)
+}
+
+func (s *SQL) DeleteException(id int) error {
+	return s.exec("DELETE FROM exceptions WHERE id = ?", id)
+}
+
+/* EXCEPTIONS */
+/* BOOKINGS */
+// AddBooking locks the candidate and the interviewers, so that concurrent
+// bookings of the same people wait for each other, and returns a
+// *ConflictError when one of them already has an active booking overlapping
+// the new one.
+func (s *SQL) AddBooking(booking *model.Booking) error {
+	return s.transaction(func(tx *SQL) error {
+		interviewerIds, err := tx.lockPeople(*booking)
+		if err != nil {
+			return err
+		}
+		if err := tx.checkConflicts(*booking, interviewerIds); err != nil {
+			return err
+		}
+
+		query := "INSERT INTO bookings (candidate_id, start_time, end_time, status, created_date) VALUES (?, ?, ?, ?, " + s.Dialect.Now() + ")"
+		id, err := tx.insert(query, booking.Candidate.Id, timestamp(booking.Start), timestamp(booking.End), booking.Status)
+		if err != nil {
+			return err
+		}
+		for _, interviewer := range booking.Interviewers {
+			if err = tx.exec("INSERT INTO bookings_interviewers (booking_id, interviewer_id) VALUES (?, ?)", id, interviewer.Id); err != nil {
+				return err
+			}
+		}
+		booking.Id = id
+		return tx.addEvent(*booking, "")
+	})
+}
+
+func (s *SQL) GetBooking(id int) (model.Booking, error) {
+	booking := model.Booking{}
+	query := "SELECT id, candidate_id, start_time, end_time, status FROM bookings WHERE id = ?"
+	err := s.queryRow(query, id).Scan(&booking.Id, &booking.Candidate.Id, utcTime{&booking.Start}, utcTime{&booking.End}, &booking.Status)
+	if err = s.rowError(err); err != nil {
+		return booking, err
+	}
+	booking.Interviewers, err = s.bookingInterviewers(booking.Id)
+	return booking, err
+}
+
+func (s *SQL) GetAllBookings(statuses []string) ([]model.Booking, error) {
+	bookings := []model.Booking{}
+	query := "SELECT id, candidate_id, start_time, end_time, status FROM bookings"
+	args := []interface{}{}
+	if len(statuses) > 0 {
+		placeholders := []string{}
+		for _, status := range statuses {
+			args = append(args, status)
+			placeholders = append(placeholders, "?")
+		}
+		query += " WHERE status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	rows, err := s.query(query+" ORDER BY start_time, id", args...)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return bookings, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		booking := model.Booking{}
+		if err = rows.Scan(&booking.Id, &booking.Candidate.Id, utcTime{&booking.Start}, utcTime{&booking.End}, &booking.Status); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return bookings, err
+		}
+		bookings = append(bookings, booking)
+	}
+	if err = rows.Err(); err != nil {
+		return bookings, err
+	}
+
+	for i := range bookings {
+		bookings[i].Interviewers, err = s.bookingInterviewers(bookings[i].Id)
+		if err != nil {
+			return bookings, err
+		}
+	}
+	return bookings, nil
+}
+
+// SetBookingStatus locks the booking while checking the lifecycle allows
+// the change, returning a *model.TransitionError when it doesn't.
+func (s *SQL) SetBookingStatus(id int, status string) (model.Booking, error) {
+	booking := model.Booking{}
+	err := s.transaction(func(tx *SQL) error {
+		current, err := tx.lockBooking(id)
+		if err != nil {
+			return err
+		}
+		if err := model.CheckTransition(current, status); err != nil {
+			return err
+		}
+		if err := tx.exec("UPDATE bookings SET status = ? WHERE id = ?", status, id); err != nil {
+			return err
+		}
+		if booking, err = tx.GetBooking(id); err != nil {
+			return err
+		}
+		return tx.addEvent(booking, current)
+	})
+	return booking, err
+}
+
+// MoveBooking locks the people of the booking like AddBooking, then the
+// booking itself, before moving it and marking it rescheduled.
+func (s *SQL) MoveBooking(id int, start, end time.Time) (model.Booking, error) {
+	booking := model.Booking{}
+	err := s.transaction(func(tx *SQL) error {
+		var err error
+		if booking, err = tx.GetBooking(id); err != nil {
+			return err
+		}
+		interviewerIds, err := tx.lockPeople(booking)
+		if err != nil {
+			return err
+		}
+		current, err := tx.lockBooking(id)
+		if err != nil {
+			return err
+		}
+		if err = model.CheckTransition(current, model.BookingRescheduled); err != nil {
+			return err
+		}
+		booking.Start, booking.End, booking.Status = start, end, model.BookingRescheduled
+		if err = tx.checkConflicts(booking, interviewerIds); err != nil {
+			return err
+		}
+		query := "UPDATE bookings SET start_time = ?, end_time = ?, status = ? WHERE id = ?"
+		if err = tx.exec(query, timestamp(start), timestamp(end), booking.Status, id); err != nil {
+			return err
+		}
+		return tx.addEvent(booking, current)
+	})
+	return booking, err
+}
+
+func (s *SQL) GetBookingHistory(id int) ([]model.BookingEvent, error) {
+	history := []model.BookingEvent{}
+	query := "SELECT status, previous_status, start_time, end_time, created_date FROM booking_events WHERE booking_id = ? ORDER BY id"
+	rows, err := s.query(query, id)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return history, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		event := model.BookingEvent{}
+		err = rows.Scan(&event.Status, &event.PreviousStatus, utcTime{&event.Start}, utcTime{&event.End}, utcTime{&event.At})
+		if err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return history, err
+		}
+		history = append(history, event)
+	}
+	return history, rows.Err()
+}
+
+// Records the current status and times of the booking, previously in the
+// previous status
+func (s *SQL) addEvent(booking model.Booking, previous string) error {
+	query := "INSERT INTO booking_events (booking_id, status, previous_status, start_time, end_time, created_date) VALUES (?, ?, ?, ?, ?, ?)"
+	return s.exec(query, booking.Id, booking.Status, previous, timestamp(booking.Start), timestamp(booking.End), timestamp(time.Now()))
+}
+
+func (s *SQL) GetBookings(owner Owner, personId int) ([]model.Booking, error) {
+	query := "SELECT id FROM bookings WHERE candidate_id = ? ORDER BY start_time, id"
+	if owner == InterviewerOwner {
+		query = "SELECT booking_id FROM bookings_interviewers b JOIN bookings ON bookings.id = b.booking_id" +
+			" WHERE b.interviewer_id = ? ORDER BY bookings.start_time, bookings.id"
+	}
+	return s.bookingsById(query, personId)
+}
+
+// Locks the candidate and the interviewers of the booking, returning the
+// interviewer ids
+func (s *SQL) lockPeople(booking model.Booking) ([]int, error) {
+	if err := s.lockPerson(CandidateOwner, booking.Candidate.Id); err != nil {
+		return nil, err
+	}
+	interviewerIds := []int{}
+	for _, interviewer := range booking.Interviewers {
+		interviewerIds = append(interviewerIds, interviewer.Id)
+	}
+	// Always locked in the same order to avoid deadlocks
+	sort.Ints(interviewerIds)
+	for _, id := range interviewerIds {
+		if err := s.lockPerson(InterviewerOwner, id); err != nil {
+			return nil, err
+		}
+	}
+	return interviewerIds, nil
+}
+
+func (s *SQL) lockPerson(owner Owner, id int) error {
+	var found int
+	query := "SELECT id FROM " + string(owner) + "s WHERE id = ?" + s.Dialect.ForUpdate()
+	return s.rowError(s.queryRow(query, id).Scan(&found))
+}
+
+// Locks the booking and returns its status
+func (s *SQL) lockBooking(id int) (string, error) {
+	var status string
+	query := "SELECT status FROM bookings WHERE id = ?" + s.Dialect.ForUpdate()
+	return status, s.rowError(s.queryRow(query, id).Scan(&status))
+}
+
+// Returns a *ConflictError with the first other active booking of the
+// candidate or of one of the interviewers overlapping the booking
+func (s *SQL) checkConflicts(booking model.Booking, interviewerIds []int) error {
+	args := []interface{}{booking.Id, model.BookingCancelled, timestamp(booking.End), timestamp(booking.Start), booking.Candidate.Id}
+	placeholders := []string{}
+	for _, id := range interviewerIds {
+		args = append(args, id)
+		placeholders = append(placeholders, "?")
+	}
+	// A booking whose interviewers were all deleted only has its candidate
+	people := "candidate_id = ?"
+	if len(placeholders) > 0 {
+		people += " OR id IN (SELECT booking_id FROM bookings_interviewers WHERE interviewer_id IN (" + strings.Join(placeholders, ", ") + "))"
+	}
+	query := "SELECT id FROM bookings WHERE id <> ? AND status <> ? AND start_time < ? AND end_time > ?" +
+		" AND (" + people + ")" +
+		" ORDER BY start_time, id" + s.Dialect.ForUpdate()
+	conflicts, err := s.bookingsById(query, args...)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &ConflictError{Booking: conflicts[0]}
+	}
+	return nil
+}
+
+// Reads the bookings whose ids the query selects, in its order
+func (s *SQL) bookingsById(query string, args ...interface{}) ([]model.Booking, error) {
+	bookings := []model.Booking{}
+	ids, err := s.ids(query, args...)
+	if err != nil {
+		return bookings, err
+	}
+	for _, id := range ids {
+		booking, err := s.GetBooking(id)
+		if err != nil {
+			return bookings, err
+		}
+		bookings = append(bookings, booking)
+	}
+	return bookings, nil
+}
+
+func (s *SQL) bookingInterviewers(bookingId int) ([]model.Interviewer, error) {
+	interviewers := []model.Interviewer{}
+	rows, err := s.query("SELECT interviewer_id FROM bookings_interviewers WHERE booking_id = ? ORDER BY id", bookingId)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return interviewers, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		interviewer := model.Interviewer{}
+		if err = rows.Scan(&interviewer.Id); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return interviewers, err
+		}
+		interviewers = append(interviewers, interviewer)
+	}
+	return interviewers, rows.Err()
+}
+
+/* BOOKINGS */
+/* HOLDS */
+func (s *SQL) AddHold(hold *model.Hold) error {
+	return s.transaction(func(tx *SQL) error {
+		// Locked like a booking, so that two overlapping holds or a hold and
+		// a booking can't both pass the checks
+		booking := model.Booking{Start: hold.Start, End: hold.End, Candidate: hold.Candidate, Interviewers: hold.Interviewers}
+		interviewerIds, err := tx.lockPeople(booking)
+		if err != nil {
+			return err
+		}
+		if err = tx.checkConflicts(booking, interviewerIds); err != nil {
+			return err
+		}
+		if err = tx.checkHoldConflicts(*hold, interviewerIds); err != nil {
+			return err
+		}
+		query := "INSERT INTO holds (candidate_id, start_time, end_time, expires_at, created_date) VALUES (?, ?, ?, ?, ?)"
+		id, err := tx.insert(query, hold.Candidate.Id, timestamp(hold.Start), timestamp(hold.End), timestamp(hold.ExpiresAt), timestamp(time.Now()))
+		if err != nil {
+			return err
+		}
+		for _, interviewer := range hold.Interviewers {
+			if err = tx.exec("INSERT INTO holds_interviewers (hold_id, interviewer_id) VALUES (?, ?)", id, interviewer.Id); err != nil {
+				return err
+			}
+		}
+		hold.Id = id
+		return nil
+	})
+}
+
+func (s *SQL) GetHold(id int) (model.Hold, error) {
+	hold := model.Hold{}
+	query := "SELECT id, candidate_id, start_time, end_time, expires_at FROM holds WHERE id = ?"
+	err := s.queryRow(query, id).Scan(&hold.Id, &hold.Candidate.Id, utcTime{&hold.Start}, utcTime{&hold.End}, utcTime{&hold.ExpiresAt})
+	if err = s.rowError(err); err != nil {
+		return hold, err
+	}
+	rows, err := s.query("SELECT interviewer_id FROM holds_interviewers WHERE hold_id = ? ORDER BY id", id)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return hold, err
+	}
+	defer rows.Close()
+	hold.Interviewers = []model.Interviewer{}
+	for rows.Next() {
+		interviewer := model.Interviewer{}
+		if err = rows.Scan(&interviewer.Id); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return hold, err
+		}
+		hold.Interviewers = append(hold.Interviewers, interviewer)
+	}
+	return hold, rows.Err()
+}
+
+func (s *SQL) GetAllHolds() ([]model.Hold, error) {
+	return s.holdsById("SELECT id FROM holds ORDER BY start_time, id")
+}
+
+func (s *SQL) GetHolds(interviewerId int) ([]model.Hold, error) {
+	query := "SELECT hold_id FROM holds_interviewers h JOIN holds ON holds.id = h.hold_id" +
+		" WHERE h.interviewer_id = ? ORDER BY holds.start_time, holds.id"
+	return s.holdsById(query, interviewerId)
+}
+
+func (s *SQL) DeleteHold(id int) error {
+	return s.exec("DELETE FROM holds WHERE id = ?", id)
+}
+
+func (s *SQL) DeleteExpiredHolds(now time.Time) (int, error) {
+	result, err := s.runner().Exec(s.Dialect.Rebind("DELETE FROM holds WHERE expires_at <= ?"), timestamp(now))
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return 0, err
+	}
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+// Returns a *HoldConflictError with the first active hold of one of the
+// interviewers for another candidate overlapping the hold
+func (s *SQL) checkHoldConflicts(hold model.Hold, interviewerIds []int) error {
+	if len(interviewerIds) == 0 {
+		return nil
+	}
+	args := []interface{}{hold.Candidate.Id, timestamp(time.Now()), timestamp(hold.End), timestamp(hold.Start)}
+	placeholders := []string{}
+	for _, id := range interviewerIds {
+		args = append(args, id)
+		placeholders = append(placeholders, "?")
+	}
+	query := "SELECT id FROM holds WHERE candidate_id <> ? AND expires_at > ? AND start_time < ? AND end_time > ?" +
+		" AND id IN (SELECT hold_id FROM holds_interviewers WHERE interviewer_id IN (" + strings.Join(placeholders, ", ") + "))" +
+		" ORDER BY start_time, id"
+	conflicts, err := s.holdsById(query, args...)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &HoldConflictError{Hold: conflicts[0]}
+	}
+	return nil
+}
+
+// Reads the holds whose ids the query selects, in its order
+func (s *SQL) holdsById(query string, args ...interface{}) ([]model.Hold, error) {
+	holds := []model.Hold{}
+	ids, err := s.ids(query, args...)
+	if err != nil {
+		return holds, err
+	}
+	for _, id := range ids {
+		hold, err := s.GetHold(id)
+		if err != nil {
+			return holds, err
+		}
+		holds = append(holds, hold)
+	}
+	return holds, nil
+}
+
+/* HOLDS */
+/* CALENDAR TOKENS */
+func (s *SQL) GetCalendarToken(owner Owner, personId int) (string, error) {
+	var token string
+	query := "SELECT calendar_token FROM " + string(owner) + "s WHERE id = ?"
+	return token, s.rowError(s.queryRow(query, personId).Scan(&token))
+}
+
+func (s *SQL) SetCalendarToken(owner Owner, personId int, token string) error {
+	if err := s.personExists(owner, personId); err != nil {
+		return err
+	}
+	return s.exec("UPDATE "+string(owner)+"s SET calendar_token = ? WHERE id = ?", token, personId)
+}
+
+func (s *SQL) GetCalDAVPassword(interviewerId int) (string, error) {
+	var password string
+	query := "SELECT caldav_password FROM interviewers WHERE id = ?"
+	return password, s.rowError(s.queryRow(query, interviewerId).Scan(&password))
+}
+
+func (s *SQL) SetCalDAVPassword(interviewerId int, password string) error {
+	if err := s.personExists(InterviewerOwner, interviewerId); err != nil {
+		return err
+	}
+	return s.exec("UPDATE interviewers SET caldav_password = ? WHERE id = ?", password, interviewerId)
+}
+
+/* CALENDAR TOKENS */
+/* CALENDARS */
+// WriteCalendar locks the person and writes the calendar in a transaction.
+func (s *SQL) WriteCalendar(write CalendarWrite) error {
+	return s.transaction(func(tx *SQL) error {
+		if err := tx.lockPerson(write.Owner, write.PersonId); err != nil {
+			return err
+		}
+		slots, err := tx.GetSlots(write.Owner, write.PersonId)
+		if err != nil {
+			return err
+		}
+		updated := updatedSlots(write)
+		for _, slot := range slots {
+			if updated[slot.Id] {
+				delete(updated, slot.Id)
+			} else if write.deletes(slot) {
+				if err = tx.DeleteSlot(slot.Id); err != nil {
+					return err
+				}
+			}
+		}
+		// The slots to update are the person's
+		if len(updated) > 0 {
+			return ErrNotFound
+		}
+		exceptions, err := tx.GetExceptions(write.Owner, write.PersonId)
+		if err != nil {
+			return err
+		}
+		for _, exception := range exceptions {
+			if write.replaces(exception.Uid) {
+				if err = tx.DeleteException(exception.Id); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, slot := range write.Slots {
+			slot.PersonId = write.PersonId
+			if slot.Id != 0 {
+				err = tx.UpdateSlot(*slot)
+			} else {
+				err = tx.AddSlot(write.Owner, slot)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		for _, exception := range write.Exceptions {
+			exception.PersonId = write.PersonId
+			if err = tx.AddException(write.Owner, exception); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/* CALENDARS */
+
+// Reads the ids the query selects, closing the rows before they are used to
+// query again
+func (s *SQL) ids(query string, args ...interface{}) ([]int, error) {
+	ids := []int{}
+	rows, err := s.query(query, args...)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return ids, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			log.Println("Database Scan Error ::", err.Error())
+			return ids, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Runs fn with a copy of the store whose queries run in a transaction,
+// committed when fn returns nil and rolled back otherwise
+func (s *SQL) transaction(fn func(tx *SQL) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		log.Println("Database Transaction Error ::", err.Error())
+		return err
+	}
+	if err = fn(&SQL{DB: s.DB, Dialect: s.Dialect, tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit(); err != nil {
+		log.Println("Database Transaction Error ::", err.Error())
+	}
+	return err
+}
+
+// The queries of the store run either on the database or in a transaction
+type runner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func (s *SQL) runner() runner {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.DB
+}
+
+func (s *SQL) insert(query string, args ...interface{}) (int, error) {
+	if s.Dialect.Returning() {
+		var id int
+		query = strings.TrimSuffix(query, ";") + " RETURNING id;"
+		if err := s.queryRow(query, args...).Scan(&id); err != nil {
+			log.Println("Database Query Error ::", err.Error())
+			return 0, err
+		}
+		return id, nil
+	}
+	result, err := s.runner().Exec(s.Dialect.Rebind(query), args...)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+		return 0, err
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		log.Println("Database Last Insert Id Error ::", err.Error())
+		return 0, err
+	}
+	return int(id), nil
+}
+
+func (s *SQL) exec(query string, args ...interface{}) error {
+	_, err := s.runner().Exec(s.Dialect.Rebind(query), args...)
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+	}
+	return err
+}
+
+func (s *SQL) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.runner().Query(s.Dialect.Rebind(query), args...)
+}
+
+func (s *SQL) queryRow(query string, args ...interface{}) *sql.Row {
+	return s.runner().QueryRow(s.Dialect.Rebind(query), args...)
+}
+
+func (s *SQL) rowError(err error) error {
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		log.Println("Database Query Error ::", err.Error())
+	}
+	return err
+}
+
+// Timestamps are stored in UTC as "2006-01-02 15:04:05", which every
+// supported database accepts for its datetime columns
+func timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05")
+}
+
+// Reads a datetime column into a UTC time. MySQL without parseTime sends
+// bytes, the other drivers a time.Time.
+type utcTime struct {
+	t *time.Time
+}
+
+func (u utcTime) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*u.t = v.UTC()
+		return nil
+	case []byte:
+		return u.parse(string(v))
+	case string:
+		return u.parse(v)
+	}
+	return fmt.Errorf("store: cannot scan %T into a time", src)
+}
+
+func (u utcTime) parse(value string) error {
+	parsed, err := time.Parse("2006-01-02 15:04:05", value)
+	if err != nil {
+		return fmt.Errorf("store: invalid datetime %q", value)
+	}
+	*u.t = parsed
+	return nil
+}
@@ -0,0 +1,183 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/paulofeitor/kilabs-api/app/model"
+)
+
+// ErrNotFound is returned when the requested record does not exist.
+var ErrNotFound = errors.New("store: record not found")
+
+// ConflictError is returned when a booking overlaps an active booking of the
+// same candidate or of one of the same interviewers.
+type ConflictError struct {
+	Booking model.Booking
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("store: conflicts with booking %d", e.Booking.Id)
+}
+
+// HoldConflictError is returned when a hold overlaps an active hold of one
+// of the same interviewers for another candidate.
+type HoldConflictError struct {
+	Hold model.Hold
+}
+
+func (e *HoldConflictError) Error() string {
+	return fmt.Sprintf("store: conflicts with hold %d", e.Hold.Id)
+}
+
+// Owner identifies which kind of person a slot or an exception belongs to.
+type Owner string
+
+const (
+	CandidateOwner   Owner = "candidate"
+	InterviewerOwner Owner = "interviewer"
+)
+
+// Column returns the slots and exceptions column that references the owner.
+func (o Owner) Column() string {
+	return string(o) + "_id"
+}
+
+type CandidateStore interface {
+	AddCandidate(candidate *model.Candidate) error
+	GetCandidate(id int) (model.Candidate, error)
+	GetAllCandidates() ([]model.Candidate, error)
+	UpdateCandidate(candidate model.Candidate) error
+	DeleteCandidate(id int) error
+}
+
+type InterviewerStore interface {
+	AddInterviewer(interviewer *model.Interviewer) error
+	GetInterviewer(id int) (model.Interviewer, error)
+	GetAllInterviewers() ([]model.Interviewer, error)
+	UpdateInterviewer(interviewer model.Interviewer) error
+	DeleteInterviewer(id int) error
+}
+
+// Store gathers every store, as implemented by SQL and Memory.
+type Store interface {
+	CandidateStore
+	InterviewerStore
+	SlotStore
+	ExceptionStore
+	BookingStore
+	HoldStore
+	CalendarTokenStore
+	CalendarStore
+}
+
+type SlotStore interface {
+	AddSlot(owner Owner, slot *model.Slot) error
+	GetSlots(owner Owner, personId int) ([]model.Slot, error)
+	UpdateSlot(slot model.Slot) error
+	DeleteSlot(id int) error
+}
+
+type ExceptionStore interface {
+	AddException(owner Owner, exception *model.Exception) error
+	GetExceptions(owner Owner, personId int) ([]model.Exception, error)
+	UpdateException(exception model.Exception) error
+	DeleteException(id int) error
+}
+
+type BookingStore interface {
+	AddBooking(booking *model.Booking) error
+	GetBooking(id int) (model.Booking, error)
+	// Every booking by start time, only the ones with the given statuses
+	// when there are any
+	GetAllBookings(statuses []string) ([]model.Booking, error)
+	// The bookings of a candidate or an interviewer, by start time
+	GetBookings(owner Owner, personId int) ([]model.Booking, error)
+	// Returns a *model.TransitionError when the booking can't move to status
+	SetBookingStatus(id int, status string) (model.Booking, error)
+	GetBookingHistory(id int) ([]model.BookingEvent, error)
+	// Moves the booking from start to end as rescheduled, returning a
+	// *ConflictError or a *model.TransitionError when it can't
+	MoveBooking(id int, start, end time.Time) (model.Booking, error)
+}
+
+type HoldStore interface {
+	// Adds the hold unless it overlaps an active booking of its people, a
+	// *ConflictError, or an active hold of one of its interviewers for
+	// another candidate, a *HoldConflictError
+	AddHold(hold *model.Hold) error
+	GetHold(id int) (model.Hold, error)
+	// Every hold by start time, including the expired ones not deleted yet
+	GetAllHolds() ([]model.Hold, error)
+	// The holds of an interviewer, by start time
+	GetHolds(interviewerId int) ([]model.Hold, error)
+	DeleteHold(id int) error
+	// Deletes the holds expired at now, returning how many there were
+	DeleteExpiredHolds(now time.Time) (int, error)
+}
+
+// CalendarTokenStore keeps the secret of the iCalendar feed of each person
+// and the CalDAV password of each interviewer, "" when they have none yet.
+type CalendarTokenStore interface {
+	GetCalendarToken(owner Owner, personId int) (string, error)
+	SetCalendarToken(owner Owner, personId int, token string) error
+	// Apart from the feed token, which only reads the calendar, as it lets
+	// a client change the slots
+	GetCalDAVPassword(interviewerId int) (string, error)
+	SetCalDAVPassword(interviewerId int, password string) error
+}
+
+// CalendarWrite is a change of the slots and exceptions of a person coming
+// from a calendar. The slots of the Deleted ids and the entries named after
+// one of the Replaced UIDs, with the UID or with an occurrence of it as in
+// "UID/20261104T090000Z", are deleted, but for the Slots being updated. Then
+// the Slots with an Id are updated and the others added, as are the
+// Exceptions.
+type CalendarWrite struct {
+	Owner      Owner
+	PersonId   int
+	Deleted    []int
+	Replaced   []string
+	Slots      []*model.Slot
+	Exceptions []*model.Exception
+}
+
+// CalendarStore writes the imported and CalDAV calendars, every change of a
+// CalendarWrite or none of them.
+type CalendarStore interface {
+	// Returns ErrNotFound when the person or a slot to update doesn't exist
+	WriteCalendar(write CalendarWrite) error
+}
+
+// Whether the slot is deleted by the write, unless it is updated
+func (w CalendarWrite) deletes(slot model.Slot) bool {
+	for _, id := range w.Deleted {
+		if slot.Id == id {
+			return true
+		}
+	}
+	return w.replaces(slot.Uid)
+}
+
+// Whether uid is one of the Replaced UIDs or one of their occurrences
+func (w CalendarWrite) replaces(uid string) bool {
+	for _, replaced := range w.Replaced {
+		if uid == replaced || strings.HasPrefix(uid, replaced+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// The ids of the slots to update
+func updatedSlots(write CalendarWrite) map[int]bool {
+	updated := map[int]bool{}
+	for _, slot := range write.Slots {
+		if slot.Id != 0 {
+			updated[slot.Id] = true
+		}
+	}
+	return updated
+}
@@ -0,0 +1,615 @@
+package store_test
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/paulofeitor/kilabs-api/app/migrations"
+	"github.com/paulofeitor/kilabs-api/app/model"
+	"github.com/paulofeitor/kilabs-api/app/store"
+)
+
+// The same contract runs against every store. MySQL and PostgreSQL need a
+// DSN of a scratch database, whose tables are dropped and created again:
+//
+//	KILABS_TEST_MYSQL_DSN="root:root@tcp(localhost:8889)/kilabs_test"
+//	KILABS_TEST_POSTGRES_DSN="host=localhost user=postgres dbname=kilabs_test sslmode=disable"
+
+func TestMemory(t *testing.T) {
+	testStore(t, func(t *testing.T) store.Store {
+		return store.NewMemory()
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	testStore(t, func(t *testing.T) store.Store {
+		file := filepath.Join(t.TempDir(), "kilabs.db")
+		return sqlStore(t, "sqlite3", file+"?_foreign_keys=on&_txlock=immediate")
+	})
+}
+
+func TestMySQL(t *testing.T) {
+	dsn := os.Getenv("KILABS_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("KILABS_TEST_MYSQL_DSN is not set")
+	}
+	testStore(t, func(t *testing.T) store.Store {
+		return sqlStore(t, "mysql", dsn)
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("KILABS_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("KILABS_TEST_POSTGRES_DSN is not set")
+	}
+	testStore(t, func(t *testing.T) store.Store {
+		return sqlStore(t, "postgres", dsn)
+	})
+}
+
+// A SQL store on an empty schema: every migration is reverted, which also
+// checks the down migrations, then applied again
+func sqlStore(t *testing.T, driver, dsn string) store.Store {
+	t.Helper()
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if driver == "sqlite3" {
+		db.SetMaxOpenConns(1)
+	}
+	dialect, err := store.NewDialect(driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := migrations.New(db, dialect.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	for {
+		reverted, err := migrator.Down()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reverted == nil {
+			break
+		}
+	}
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrator.Migrations) {
+		t.Fatalf("applied %d migrations of %d", len(applied), len(migrator.Migrations))
+	}
+	return store.NewSQL(db, dialect)
+}
+
+func testStore(t *testing.T, newStore func(t *testing.T) store.Store) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s store.Store)
+	}{
+		{"candidates", testCandidates},
+		{"interviewers", testInterviewers},
+		{"slots", testSlots},
+		{"exceptions", testExceptions},
+		{"bookings", testBookings},
+		{"booking conflicts", testBookingConflicts},
+		{"holds", testHolds},
+		{"hold conflicts", testHoldConflicts},
+		{"calendar tokens", testCalendarTokens},
+		{"calendar writes", testWriteCalendar},
+		{"deleting people", testDeletingPeople},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, newStore(t))
+		})
+	}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// November 4th 2026 at hour UTC
+func at(hour int) time.Time {
+	return time.Date(2026, time.November, 4, hour, 0, 0, 0, time.UTC)
+}
+
+func addPeople(t *testing.T, s store.Store) (model.Candidate, model.Interviewer, model.Interviewer) {
+	t.Helper()
+	candidate := model.Candidate{Name: "Carl", TimeZone: "Europe/Lisbon"}
+	check(t, s.AddCandidate(&candidate))
+	ingrid := model.Interviewer{Name: "Ingrid", TimeZone: "Europe/Berlin"}
+	check(t, s.AddInterviewer(&ingrid))
+	ian := model.Interviewer{Name: "Ian", TimeZone: "UTC"}
+	check(t, s.AddInterviewer(&ian))
+	return candidate, ingrid, ian
+}
+
+func testCandidates(t *testing.T, s store.Store) {
+	all, err := s.GetAllCandidates()
+	check(t, err)
+	if len(all) != 0 {
+		t.Errorf("new store has candidates %v", all)
+	}
+	first, second := model.Candidate{Name: "Carl", TimeZone: "UTC"}, model.Candidate{Name: "Cora", TimeZone: "Europe/Lisbon"}
+	check(t, s.AddCandidate(&first))
+	check(t, s.AddCandidate(&second))
+	if first.Id != 1 || second.Id != 2 {
+		t.Errorf("ids %d and %d, want 1 and 2", first.Id, second.Id)
+	}
+	got, err := s.GetCandidate(second.Id)
+	check(t, err)
+	if got != second {
+		t.Errorf("GetCandidate = %+v, want %+v", got, second)
+	}
+	second.Name = "Coralie"
+	check(t, s.UpdateCandidate(second))
+	all, err = s.GetAllCandidates()
+	check(t, err)
+	if !reflect.DeepEqual(all, []model.Candidate{first, second}) {
+		t.Errorf("GetAllCandidates = %+v", all)
+	}
+	// Unknown ids are only an error when reading
+	check(t, s.UpdateCandidate(model.Candidate{Id: 9, Name: "Nobody", TimeZone: "UTC"}))
+	check(t, s.DeleteCandidate(9))
+	check(t, s.DeleteCandidate(first.Id))
+	if _, err = s.GetCandidate(first.Id); err != store.ErrNotFound {
+		t.Errorf("GetCandidate of a deleted candidate = %v, want ErrNotFound", err)
+	}
+}
+
+func testInterviewers(t *testing.T, s store.Store) {
+	first, second := model.Interviewer{Name: "Ingrid", TimeZone: "UTC"}, model.Interviewer{Name: "Ian", TimeZone: "Asia/Tokyo"}
+	check(t, s.AddInterviewer(&first))
+	check(t, s.AddInterviewer(&second))
+	if first.Id != 1 || second.Id != 2 {
+		t.Errorf("ids %d and %d, want 1 and 2", first.Id, second.Id)
+	}
+	first.TimeZone = "Europe/Berlin"
+	check(t, s.UpdateInterviewer(first))
+	all, err := s.GetAllInterviewers()
+	check(t, err)
+	if !reflect.DeepEqual(all, []model.Interviewer{first, second}) {
+		t.Errorf("GetAllInterviewers = %+v", all)
+	}
+	check(t, s.DeleteInterviewer(second.Id))
+	if _, err = s.GetInterviewer(second.Id); err != store.ErrNotFound {
+		t.Errorf("GetInterviewer of a deleted interviewer = %v, want ErrNotFound", err)
+	}
+}
+
+func testSlots(t *testing.T, s store.Store) {
+	candidate, ingrid, _ := addPeople(t, s)
+	date := model.Date{Year: 2026, Month: time.November, Day: 2}
+	weekly := model.Slot{PersonId: candidate.Id, InitialTime: 9 * 3600, FinalTime: 12 * 3600, Weekdays: []time.Weekday{time.Monday, time.Wednesday}}
+	recurring := model.Slot{PersonId: candidate.Id, Date: &date, InitialTime: 14 * 3600, FinalTime: model.EndOfDay, RRule: "FREQ=WEEKLY;BYDAY=MO", Uid: "standup@example.com"}
+	check(t, s.AddSlot(store.CandidateOwner, &weekly))
+	check(t, s.AddSlot(store.CandidateOwner, &recurring))
+	other := model.Slot{PersonId: ingrid.Id, InitialTime: 8 * 3600, FinalTime: 9 * 3600, Weekdays: []time.Weekday{time.Friday}}
+	check(t, s.AddSlot(store.InterviewerOwner, &other))
+	if weekly.Id != 1 || recurring.Id != 2 || other.Id != 3 {
+		t.Errorf("ids %d, %d and %d, want 1, 2 and 3", weekly.Id, recurring.Id, other.Id)
+	}
+	recurring.Weekdays = []time.Weekday{}
+
+	slots, err := s.GetSlots(store.CandidateOwner, candidate.Id)
+	check(t, err)
+	if !reflect.DeepEqual(slots, []model.Slot{weekly, recurring}) {
+		t.Errorf("GetSlots = %+v, want %+v", slots, []model.Slot{weekly, recurring})
+	}
+
+	// The owner and the UID never change
+	weekly.Weekdays = []time.Weekday{time.Friday}
+	weekly.FinalTime = 10 * 3600
+	check(t, s.UpdateSlot(weekly))
+	slots, err = s.GetSlots(store.CandidateOwner, candidate.Id)
+	check(t, err)
+	if len(slots) != 2 || !reflect.DeepEqual(slots[0], weekly) {
+		t.Errorf("updated slot %+v, want %+v", slots, weekly)
+	}
+
+	check(t, s.DeleteSlot(weekly.Id))
+	slots, err = s.GetSlots(store.CandidateOwner, candidate.Id)
+	check(t, err)
+	if len(slots) != 1 || slots[0].Id != recurring.Id {
+		t.Errorf("GetSlots after the deletion = %+v", slots)
+	}
+	if err = s.AddSlot(store.CandidateOwner, &model.Slot{PersonId: 9, InitialTime: 0, FinalTime: 3600}); err != store.ErrNotFound {
+		t.Errorf("AddSlot of an unknown person = %v, want ErrNotFound", err)
+	}
+}
+
+func testExceptions(t *testing.T, s store.Store) {
+	_, ingrid, _ := addPeople(t, s)
+	day := model.Date{Year: 2026, Month: time.November, Day: 4}
+	exception := model.Exception{PersonId: ingrid.Id, From: day, InitialTime: 9 * 3600, To: day.AddDays(1), FinalTime: 12 * 3600, Reason: "Conference", Uid: "conference@example.com"}
+	check(t, s.AddException(store.InterviewerOwner, &exception))
+	if exception.Id != 1 {
+		t.Errorf("id %d, want 1", exception.Id)
+	}
+	exceptions, err := s.GetExceptions(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if !reflect.DeepEqual(exceptions, []model.Exception{exception}) {
+		t.Errorf("GetExceptions = %+v, want %+v", exceptions, exception)
+	}
+	exception.Reason = "Holidays"
+	check(t, s.UpdateException(exception))
+	exceptions, err = s.GetExceptions(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if !reflect.DeepEqual(exceptions, []model.Exception{exception}) {
+		t.Errorf("updated exception %+v, want %+v", exceptions, exception)
+	}
+	check(t, s.DeleteException(exception.Id))
+	exceptions, err = s.GetExceptions(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if len(exceptions) != 0 {
+		t.Errorf("GetExceptions after the deletion = %+v", exceptions)
+	}
+	if err = s.AddException(store.CandidateOwner, &model.Exception{PersonId: 9, From: day, To: day}); err != store.ErrNotFound {
+		t.Errorf("AddException of an unknown person = %v, want ErrNotFound", err)
+	}
+}
+
+func testBookings(t *testing.T, s store.Store) {
+	candidate, ingrid, ian := addPeople(t, s)
+	later := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(14), End: at(15), Status: model.BookingProposed}
+	earlier := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid, ian}, Start: at(9), End: at(10), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&later))
+	check(t, s.AddBooking(&earlier))
+	if later.Id != 1 || earlier.Id != 2 {
+		t.Errorf("ids %d and %d, want 1 and 2", later.Id, earlier.Id)
+	}
+
+	got, err := s.GetBooking(earlier.Id)
+	check(t, err)
+	if got.Id != earlier.Id || !got.Start.Equal(at(9)) || len(got.Interviewers) != 2 || got.Status != model.BookingConfirmed {
+		t.Errorf("GetBooking = %+v", got)
+	}
+	all, err := s.GetAllBookings(nil)
+	check(t, err)
+	if len(all) != 2 || all[0].Id != earlier.Id || all[1].Id != later.Id {
+		t.Errorf("GetAllBookings = %+v, want them by start time", all)
+	}
+	proposed, err := s.GetAllBookings([]string{model.BookingProposed})
+	check(t, err)
+	if len(proposed) != 1 || proposed[0].Id != later.Id {
+		t.Errorf("proposed bookings = %+v", proposed)
+	}
+	ians, err := s.GetBookings(store.InterviewerOwner, ian.Id)
+	check(t, err)
+	if len(ians) != 1 || ians[0].Id != earlier.Id {
+		t.Errorf("bookings of Ian = %+v", ians)
+	}
+
+	confirmed, err := s.SetBookingStatus(later.Id, model.BookingConfirmed)
+	check(t, err)
+	if confirmed.Status != model.BookingConfirmed {
+		t.Errorf("SetBookingStatus = %+v", confirmed)
+	}
+	moved, err := s.MoveBooking(later.Id, at(16), at(17))
+	check(t, err)
+	if moved.Status != model.BookingRescheduled || !moved.Start.Equal(at(16)) {
+		t.Errorf("MoveBooking = %+v", moved)
+	}
+	_, err = s.SetBookingStatus(later.Id, model.BookingCancelled)
+	check(t, err)
+	if _, err = s.SetBookingStatus(later.Id, model.BookingConfirmed); err == nil {
+		t.Error("a cancelled booking was confirmed again")
+	} else if _, ok := err.(*model.TransitionError); !ok {
+		t.Errorf("SetBookingStatus of a cancelled booking = %v, want a *model.TransitionError", err)
+	}
+
+	history, err := s.GetBookingHistory(later.Id)
+	check(t, err)
+	statuses := []string{}
+	for _, event := range history {
+		statuses = append(statuses, event.PreviousStatus+">"+event.Status)
+	}
+	want := []string{">proposed", "proposed>confirmed", "confirmed>rescheduled", "rescheduled>cancelled"}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("history %v, want %v", statuses, want)
+	}
+	if !history[2].Start.Equal(at(16)) {
+		t.Errorf("the move was recorded at %v, want %v", history[2].Start, at(16))
+	}
+	if _, err = s.GetBooking(9); err != store.ErrNotFound {
+		t.Errorf("GetBooking of an unknown booking = %v, want ErrNotFound", err)
+	}
+	if err = s.AddBooking(&model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{{Id: 9}}, Start: at(18), End: at(19), Status: model.BookingConfirmed}); err != store.ErrNotFound {
+		t.Errorf("AddBooking with an unknown interviewer = %v, want ErrNotFound", err)
+	}
+}
+
+func testBookingConflicts(t *testing.T, s store.Store) {
+	candidate, ingrid, ian := addPeople(t, s)
+	other := model.Candidate{Name: "Cora", TimeZone: "UTC"}
+	check(t, s.AddCandidate(&other))
+	first := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(9), End: at(10), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&first))
+
+	tests := []struct {
+		name     string
+		booking  model.Booking
+		conflict bool
+	}{
+		{"same interviewer", model.Booking{Candidate: other, Interviewers: []model.Interviewer{ingrid}, Start: at(9), End: at(10)}, true},
+		{"same candidate", model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ian}, Start: at(8), End: at(10)}, true},
+		{"touching", model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(10), End: at(11)}, false},
+		{"other people", model.Booking{Candidate: other, Interviewers: []model.Interviewer{ian}, Start: at(9), End: at(10)}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			booking := test.booking
+			booking.Status = model.BookingConfirmed
+			err := s.AddBooking(&booking)
+			conflict, ok := err.(*store.ConflictError)
+			switch {
+			case test.conflict && !ok:
+				t.Errorf("AddBooking = %v, want a *store.ConflictError", err)
+			case test.conflict && conflict.Booking.Id != first.Id:
+				t.Errorf("conflicts with %+v, want booking %d", conflict.Booking, first.Id)
+			case !test.conflict && err != nil:
+				t.Errorf("AddBooking = %v, want no conflict", err)
+			}
+		})
+	}
+
+	// Moving into the first booking conflicts, cancelled bookings don't
+	second := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(13), End: at(14), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&second))
+	if _, err := s.MoveBooking(second.Id, at(9), at(10)); err == nil {
+		t.Error("MoveBooking into another booking succeeded")
+	} else if _, ok := err.(*store.ConflictError); !ok {
+		t.Errorf("MoveBooking = %v, want a *store.ConflictError", err)
+	}
+	_, err := s.SetBookingStatus(first.Id, model.BookingCancelled)
+	check(t, err)
+	_, err = s.MoveBooking(second.Id, at(9), at(10))
+	check(t, err)
+}
+
+func testHolds(t *testing.T, s store.Store) {
+	candidate, ingrid, ian := addPeople(t, s)
+	now := time.Now().UTC().Truncate(time.Second)
+	lasting := model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid, ian}, Start: at(11), End: at(12), ExpiresAt: now.Add(time.Hour)}
+	expired := model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(9), End: at(10), ExpiresAt: now.Add(-time.Minute)}
+	check(t, s.AddHold(&lasting))
+	check(t, s.AddHold(&expired))
+	if lasting.Id != 1 || expired.Id != 2 {
+		t.Errorf("ids %d and %d, want 1 and 2", lasting.Id, expired.Id)
+	}
+	got, err := s.GetHold(lasting.Id)
+	check(t, err)
+	if len(got.Interviewers) != 2 || !got.ExpiresAt.Equal(lasting.ExpiresAt) || !got.Start.Equal(at(11)) {
+		t.Errorf("GetHold = %+v", got)
+	}
+	all, err := s.GetAllHolds()
+	check(t, err)
+	if len(all) != 2 || all[0].Id != expired.Id {
+		t.Errorf("GetAllHolds = %+v, want them by start time", all)
+	}
+	ians, err := s.GetHolds(ian.Id)
+	check(t, err)
+	if len(ians) != 1 || ians[0].Id != lasting.Id {
+		t.Errorf("holds of Ian = %+v", ians)
+	}
+	deleted, err := s.DeleteExpiredHolds(now)
+	check(t, err)
+	if deleted != 1 {
+		t.Errorf("DeleteExpiredHolds deleted %d, want 1", deleted)
+	}
+	check(t, s.DeleteHold(lasting.Id))
+	if _, err = s.GetHold(lasting.Id); err != store.ErrNotFound {
+		t.Errorf("GetHold of a released hold = %v, want ErrNotFound", err)
+	}
+}
+
+func testHoldConflicts(t *testing.T, s store.Store) {
+	candidate, ingrid, ian := addPeople(t, s)
+	other := model.Candidate{Name: "Cora", TimeZone: "UTC"}
+	check(t, s.AddCandidate(&other))
+	now := time.Now().UTC().Truncate(time.Second)
+	first := model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(9), End: at(10), ExpiresAt: now.Add(time.Hour)}
+	check(t, s.AddHold(&first))
+	expired := model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ian}, Start: at(13), End: at(14), ExpiresAt: now.Add(-time.Minute)}
+	check(t, s.AddHold(&expired))
+	booking := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ian}, Start: at(15), End: at(16), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&booking))
+
+	tests := []struct {
+		name     string
+		hold     model.Hold
+		conflict error
+	}{
+		{"same interviewer", model.Hold{Candidate: other, Interviewers: []model.Interviewer{ian, ingrid}, Start: at(9), End: at(11)}, &store.HoldConflictError{Hold: first}},
+		{"same candidate", model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(9), End: at(10)}, nil},
+		{"touching", model.Hold{Candidate: other, Interviewers: []model.Interviewer{ingrid}, Start: at(10), End: at(11)}, nil},
+		{"expired hold", model.Hold{Candidate: other, Interviewers: []model.Interviewer{ian}, Start: at(13), End: at(14)}, nil},
+		{"booking", model.Hold{Candidate: other, Interviewers: []model.Interviewer{ian}, Start: at(15), End: at(16)}, &store.ConflictError{Booking: booking}},
+		{"booked candidate", model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(15), End: at(16)}, &store.ConflictError{Booking: booking}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hold := test.hold
+			hold.ExpiresAt = now.Add(time.Hour)
+			err := s.AddHold(&hold)
+			switch want := test.conflict.(type) {
+			case nil:
+				if err != nil {
+					t.Errorf("AddHold = %v, want no conflict", err)
+				}
+			case *store.HoldConflictError:
+				if conflict, ok := err.(*store.HoldConflictError); !ok || conflict.Hold.Id != want.Hold.Id {
+					t.Errorf("AddHold = %v, want a conflict with hold %d", err, want.Hold.Id)
+				}
+			case *store.ConflictError:
+				if conflict, ok := err.(*store.ConflictError); !ok || conflict.Booking.Id != want.Booking.Id {
+					t.Errorf("AddHold = %v, want a conflict with booking %d", err, want.Booking.Id)
+				}
+			}
+		})
+	}
+}
+
+func testCalendarTokens(t *testing.T, s store.Store) {
+	candidate, ingrid, _ := addPeople(t, s)
+	token, err := s.GetCalendarToken(store.CandidateOwner, candidate.Id)
+	check(t, err)
+	if token != "" {
+		t.Errorf("new token %q, want none", token)
+	}
+	check(t, s.SetCalendarToken(store.CandidateOwner, candidate.Id, "first"))
+	check(t, s.SetCalendarToken(store.CandidateOwner, candidate.Id, "second"))
+	check(t, s.SetCalendarToken(store.InterviewerOwner, ingrid.Id, "hers"))
+	if token, err = s.GetCalendarToken(store.CandidateOwner, candidate.Id); err != nil || token != "second" {
+		t.Errorf("GetCalendarToken = %q, %v, want the replaced token", token, err)
+	}
+	if _, err = s.GetCalendarToken(store.InterviewerOwner, 9); err != store.ErrNotFound {
+		t.Errorf("GetCalendarToken of an unknown person = %v, want ErrNotFound", err)
+	}
+
+	// The CalDAV password is apart from the feed token
+	if password, err := s.GetCalDAVPassword(ingrid.Id); err != nil || password != "" {
+		t.Errorf("new CalDAV password %q, %v, want none", password, err)
+	}
+	check(t, s.SetCalDAVPassword(ingrid.Id, "write"))
+	if password, err := s.GetCalDAVPassword(ingrid.Id); err != nil || password != "write" {
+		t.Errorf("GetCalDAVPassword = %q, %v, want the one set", password, err)
+	}
+	if token, err = s.GetCalendarToken(store.InterviewerOwner, ingrid.Id); err != nil || token != "hers" {
+		t.Errorf("GetCalendarToken = %q, %v, want the feed token unchanged", token, err)
+	}
+	if err = s.SetCalDAVPassword(9, "write"); err != store.ErrNotFound {
+		t.Errorf("SetCalDAVPassword of an unknown interviewer = %v, want ErrNotFound", err)
+	}
+}
+
+func testWriteCalendar(t *testing.T, s store.Store) {
+	_, ingrid, ian := addPeople(t, s)
+	day := model.Date{Year: 2026, Month: time.November, Day: 4}
+	none := []time.Weekday{}
+	master := model.Slot{Date: &day, InitialTime: 9 * 3600, FinalTime: 12 * 3600, Weekdays: none, RRule: "FREQ=WEEKLY", Uid: "abc", Resource: "other-name.ics"}
+	moved := model.Slot{Date: &day, InitialTime: 14 * 3600, FinalTime: 15 * 3600, Weekdays: none, Uid: "abc/20261111T090000Z"}
+	lookalike := model.Slot{Date: &day, InitialTime: 16 * 3600, FinalTime: 17 * 3600, Weekdays: none, Uid: "abcd"}
+	weekly := model.Slot{InitialTime: 8 * 3600, FinalTime: 9 * 3600, Weekdays: []time.Weekday{time.Friday}}
+	excluded := model.Exception{From: day.AddDays(14), InitialTime: 9 * 3600, To: day.AddDays(14), FinalTime: 12 * 3600, Uid: "abc/20261118T090000Z"}
+	other := model.Exception{From: day, To: day, FinalTime: model.EndOfDay, Uid: "holidays"}
+	check(t, s.WriteCalendar(store.CalendarWrite{
+		Owner:      store.InterviewerOwner,
+		PersonId:   ingrid.Id,
+		Slots:      []*model.Slot{&master, &moved, &lookalike, &weekly},
+		Exceptions: []*model.Exception{&excluded, &other},
+	}))
+	if master.Id != 1 || weekly.Id != 4 || other.Id != 2 || master.PersonId != ingrid.Id {
+		t.Errorf("added slots %+v and %+v and exception %+v", master, weekly, other)
+	}
+
+	// Rewriting the event keeps the updated slot and replaces its occurrences
+	master.FinalTime, master.Resource = 11*3600, "ignored.ics"
+	movedAgain := model.Slot{Date: &day, InitialTime: 15 * 3600, FinalTime: 16 * 3600, Weekdays: none, Uid: "abc/20261111T090000Z"}
+	check(t, s.WriteCalendar(store.CalendarWrite{
+		Owner:    store.InterviewerOwner,
+		PersonId: ingrid.Id,
+		Deleted:  []int{weekly.Id},
+		Replaced: []string{"abc"},
+		Slots:    []*model.Slot{&master, &movedAgain},
+	}))
+	slots, err := s.GetSlots(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	master.Resource = "other-name.ics"
+	if !reflect.DeepEqual(slots, []model.Slot{master, lookalike, movedAgain}) {
+		t.Errorf("slots %+v, want %+v", slots, []model.Slot{master, lookalike, movedAgain})
+	}
+	exceptions, err := s.GetExceptions(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if !reflect.DeepEqual(exceptions, []model.Exception{other}) {
+		t.Errorf("exceptions %+v, want %+v", exceptions, other)
+	}
+
+	// Nothing is written when a slot to update isn't the person's
+	stolen := slots[0]
+	added := model.Slot{Date: &day, InitialTime: 0, FinalTime: 3600}
+	err = s.WriteCalendar(store.CalendarWrite{
+		Owner:    store.InterviewerOwner,
+		PersonId: ian.Id,
+		Replaced: []string{"abc"},
+		Slots:    []*model.Slot{&added, &stolen},
+	})
+	if err != store.ErrNotFound {
+		t.Errorf("WriteCalendar of another interviewer's slot = %v, want ErrNotFound", err)
+	}
+	ians, err := s.GetSlots(store.InterviewerOwner, ian.Id)
+	check(t, err)
+	after, err := s.GetSlots(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if len(ians) != 0 || !reflect.DeepEqual(after, slots) {
+		t.Errorf("slots %+v and %+v after a failed write", ians, after)
+	}
+	if err = s.WriteCalendar(store.CalendarWrite{Owner: store.CandidateOwner, PersonId: 9}); err != store.ErrNotFound {
+		t.Errorf("WriteCalendar of an unknown person = %v, want ErrNotFound", err)
+	}
+}
+
+// Deleting a person deletes their slots and exceptions, and takes them out
+// of the bookings and holds
+func testDeletingPeople(t *testing.T, s store.Store) {
+	candidate, ingrid, ian := addPeople(t, s)
+	day := model.Date{Year: 2026, Month: time.November, Day: 4}
+	check(t, s.AddSlot(store.InterviewerOwner, &model.Slot{PersonId: ingrid.Id, InitialTime: 9 * 3600, FinalTime: 12 * 3600, Weekdays: []time.Weekday{time.Monday}}))
+	check(t, s.AddException(store.InterviewerOwner, &model.Exception{PersonId: ingrid.Id, From: day, To: day, FinalTime: model.EndOfDay}))
+	booking := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ingrid, ian}, Start: at(9), End: at(10), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&booking))
+	hold := model.Hold{Candidate: candidate, Interviewers: []model.Interviewer{ingrid}, Start: at(11), End: at(12), ExpiresAt: time.Now().Add(time.Hour)}
+	check(t, s.AddHold(&hold))
+
+	check(t, s.DeleteInterviewer(ingrid.Id))
+	slots, err := s.GetSlots(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	exceptions, err := s.GetExceptions(store.InterviewerOwner, ingrid.Id)
+	check(t, err)
+	if len(slots) != 0 || len(exceptions) != 0 {
+		t.Errorf("slots %+v and exceptions %+v left", slots, exceptions)
+	}
+	got, err := s.GetBooking(booking.Id)
+	check(t, err)
+	if len(got.Interviewers) != 1 || got.Interviewers[0].Id != ian.Id {
+		t.Errorf("booking interviewers %+v, want Ian only", got.Interviewers)
+	}
+	held, err := s.GetHold(hold.Id)
+	check(t, err)
+	if len(held.Interviewers) != 0 {
+		t.Errorf("hold interviewers %+v, want none", held.Interviewers)
+	}
+
+	// Bookings without interviewers left still move, checked against their
+	// candidate
+	later := model.Booking{Candidate: candidate, Interviewers: []model.Interviewer{ian}, Start: at(14), End: at(15), Status: model.BookingConfirmed}
+	check(t, s.AddBooking(&later))
+	check(t, s.DeleteInterviewer(ian.Id))
+	if _, err = s.MoveBooking(booking.Id, at(14), at(15)); err == nil {
+		t.Error("MoveBooking into the candidate's other booking succeeded")
+	} else if _, ok := err.(*store.ConflictError); !ok {
+		t.Errorf("MoveBooking = %v, want a *store.ConflictError", err)
+	}
+	_, err = s.MoveBooking(booking.Id, at(16), at(17))
+	check(t, err)
+}
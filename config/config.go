@@ -1,20 +1,84 @@
+// Package config loads the service configuration in layers: the defaults,
+// then an optional YAML or TOML file, then the KILABS_* environment
+// variables and finally the command line flags, each overriding the
+// previous one.
 package config
 
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
 type Config struct {
-	DB *DBConfig
+	DB       *DBConfig       `yaml:"db" toml:"db"`
+	Server   *ServerConfig   `yaml:"server" toml:"server"`
+	Matching *MatchingConfig `yaml:"matching" toml:"matching"`
+	Holds    *HoldsConfig    `yaml:"holds" toml:"holds"`
+	// "debug" also logs every request, "off" silences the logs
+	LogLevel string `yaml:"log_level" toml:"log_level"`
 }
 
 type DBConfig struct {
-	Driver  string
-	Host    string
-	Port    string
-	User    string
-	Pass    string
-	Name    string
-	Charset string
+	// "mysql", "postgres", "sqlite3", or "memory" to keep everything in
+	// process memory
+	Driver string `yaml:"driver" toml:"driver"`
+	Host   string `yaml:"host" toml:"host"`
+	Port   string `yaml:"port" toml:"port"`
+	User   string `yaml:"user" toml:"user"`
+	Pass   string `yaml:"pass" toml:"pass"`
+	Name   string `yaml:"name" toml:"name"`
+	// Only used by mysql
+	Charset string `yaml:"charset" toml:"charset"`
+	// "disable", "require", "verify-full"..., only used by postgres
+	SSLMode string `yaml:"sslmode" toml:"sslmode"`
+	// Database file, only used by sqlite3
+	File string `yaml:"file" toml:"file"`
+	// Apply the pending migrations on startup
+	Migrate bool `yaml:"migrate" toml:"migrate"`
+}
+
+type ServerConfig struct {
+	Addr         string        `yaml:"addr" toml:"addr"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+}
+
+type MatchingConfig struct {
+	// Default interview length of the slot matching
+	Duration time.Duration `yaml:"duration" toml:"duration"`
+	// Default granularity of the interview start times, counted from
+	// midnight whatever the duration
+	Step time.Duration `yaml:"step" toml:"step"`
+	// Number of days searched when a matching request has no To date, and
+	// over which the imported recurring busy events are expanded
+	HorizonDays int `yaml:"horizon_days" toml:"horizon_days"`
+}
+
+type HoldsConfig struct {
+	// How long a hold lasts when its request has no TTL, and at most
+	TTL    time.Duration `yaml:"ttl" toml:"ttl"`
+	MaxTTL time.Duration `yaml:"max_ttl" toml:"max_ttl"`
+	// How often the expired holds are deleted
+	ExpiryInterval time.Duration `yaml:"expiry_interval" toml:"expiry_interval"`
 }
 
-func GetConfig() *Config {
+var (
+	drivers   = []string{"mysql", "postgres", "sqlite3", "memory"}
+	logLevels = []string{"debug", "info", "off"}
+)
+
+func Default() *Config {
 	return &Config{
 		DB: &DBConfig{
 			Driver:  "mysql",
@@ -24,6 +88,229 @@ func GetConfig() *Config {
 			Pass:    "root",
 			Name:    "kilabs",
 			Charset: "utf8",
+			SSLMode: "disable",
+			File:    "kilabs.db",
+			Migrate: true,
+		},
+		Server: &ServerConfig{
+			Addr:         ":3000",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  time.Minute,
+		},
+		Matching: &MatchingConfig{
+			Duration:    time.Hour,
+			Step:        15 * time.Minute,
+			HorizonDays: 14,
+		},
+		Holds: &HoldsConfig{
+			TTL:            15 * time.Minute,
+			MaxTTL:         24 * time.Hour,
+			ExpiryInterval: time.Minute,
 		},
+		LogLevel: "info",
+	}
+}
+
+// Load builds the configuration from the command line arguments (without
+// the program name) and the environment. It returns the arguments left
+// after the flags, such as the migrate command.
+func Load(args []string) (*Config, []string, error) {
+	config := Default()
+	flags := flag.NewFlagSet("kilabs-api", flag.ContinueOnError)
+	configFile := flags.String("config", os.Getenv("KILABS_CONFIG"), "YAML or TOML configuration file (KILABS_CONFIG)")
+	settings := config.settings()
+	for _, s := range settings {
+		flags.String(s.flag, "", s.usage+" ("+s.env()+")")
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configFile != "" {
+		if err := config.readFile(*configFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env()); ok {
+			if err := s.set(value); err != nil {
+				return nil, nil, fmt.Errorf("config: invalid %s: %v", s.env(), err)
+			}
+		}
+	}
+	var err error
+	flags.Visit(func(f *flag.Flag) {
+		for _, s := range settings {
+			if s.flag == f.Name && err == nil {
+				if setErr := s.set(f.Value.String()); setErr != nil {
+					err = fmt.Errorf("config: invalid -%s: %v", s.flag, setErr)
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err = config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, flags.Args(), nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	problems := []string{}
+	if !oneOf(c.DB.Driver, drivers) {
+		problems = append(problems, fmt.Sprintf("db.driver %q must be one of %s", c.DB.Driver, strings.Join(drivers, ", ")))
+	}
+	switch c.DB.Driver {
+	case "mysql", "postgres":
+		if c.DB.Host == "" || c.DB.Name == "" {
+			problems = append(problems, "db.host and db.name are required by "+c.DB.Driver)
+		}
+		if _, err := strconv.Atoi(c.DB.Port); err != nil {
+			problems = append(problems, fmt.Sprintf("db.port %q is not a number", c.DB.Port))
+		}
+	case "sqlite3":
+		if c.DB.File == "" {
+			problems = append(problems, "db.file is required by sqlite3")
+		}
+	}
+	if c.Server.Addr == "" {
+		problems = append(problems, "server.addr is required")
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		problems = append(problems, "server timeouts can't be negative")
+	}
+	if c.Matching.Duration < time.Minute || c.Matching.Duration%time.Minute != 0 {
+		problems = append(problems, "matching.duration must be a whole number of minutes")
+	}
+	if c.Matching.Step < time.Minute || c.Matching.Step%time.Minute != 0 {
+		problems = append(problems, "matching.step must be a whole number of minutes")
+	}
+	if c.Matching.HorizonDays < 1 || c.Matching.HorizonDays > 366 {
+		problems = append(problems, "matching.horizon_days must be from 1 to 366")
+	}
+	if c.Holds.TTL <= 0 || c.Holds.TTL > c.Holds.MaxTTL {
+		problems = append(problems, "holds.ttl must be positive and at most holds.max_ttl")
+	}
+	if c.Holds.ExpiryInterval <= 0 {
+		problems = append(problems, "holds.expiry_interval must be positive")
+	}
+	if !oneOf(c.LogLevel, logLevels) {
+		problems = append(problems, fmt.Sprintf("log_level %q must be one of %s", c.LogLevel, strings.Join(logLevels, ", ")))
+	}
+	if len(problems) > 0 {
+		return errors.New("config: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (c *Config) readFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %v", err)
+	}
+	switch filepath.Ext(path) {
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(content, c)
+	case ".toml":
+		var meta toml.MetaData
+		meta, err = toml.Decode(string(content), c)
+		if err == nil && len(meta.Undecoded()) > 0 {
+			err = fmt.Errorf("unknown setting %s", meta.Undecoded()[0])
+		}
+	default:
+		return fmt.Errorf("config: %s must have a .yaml, .yml or .toml extension", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config: reading %s: %v", path, err)
+	}
+	return nil
+}
+
+// A setting that can be overridden by an environment variable and a flag
+type setting struct {
+	flag  string
+	usage string
+	set   func(value string) error
+}
+
+// KILABS_ followed by the flag name in upper case, db-host is KILABS_DB_HOST
+func (s setting) env() string {
+	return "KILABS_" + strings.ToUpper(strings.Replace(s.flag, "-", "_", -1))
+}
+
+func (c *Config) settings() []setting {
+	return []setting{
+		{"db-driver", "database driver: " + strings.Join(drivers, ", "), setString(&c.DB.Driver)},
+		{"db-host", "database host", setString(&c.DB.Host)},
+		{"db-port", "database port", setString(&c.DB.Port)},
+		{"db-user", "database user", setString(&c.DB.User)},
+		{"db-pass", "database password", setString(&c.DB.Pass)},
+		{"db-name", "database name", setString(&c.DB.Name)},
+		{"db-charset", "MySQL charset", setString(&c.DB.Charset)},
+		{"db-sslmode", "PostgreSQL sslmode", setString(&c.DB.SSLMode)},
+		{"db-file", "SQLite database file", setString(&c.DB.File)},
+		{"db-migrate", "apply pending migrations on startup", setBool(&c.DB.Migrate)},
+		{"addr", "listen address", setString(&c.Server.Addr)},
+		{"read-timeout", "request read timeout", setDuration(&c.Server.ReadTimeout)},
+		{"write-timeout", "response write timeout", setDuration(&c.Server.WriteTimeout)},
+		{"idle-timeout", "keep-alive idle timeout", setDuration(&c.Server.IdleTimeout)},
+		{"matching-duration", "default interview length", setDuration(&c.Matching.Duration)},
+		{"matching-step", "default interview start granularity", setDuration(&c.Matching.Step)},
+		{"matching-horizon-days", "days searched when a matching request has no To date", setInt(&c.Matching.HorizonDays)},
+		{"hold-ttl", "default hold length", setDuration(&c.Holds.TTL)},
+		{"hold-max-ttl", "longest hold", setDuration(&c.Holds.MaxTTL)},
+		{"hold-expiry-interval", "interval between expired hold deletions", setDuration(&c.Holds.ExpiryInterval)},
+		{"log-level", "log level: " + strings.Join(logLevels, ", "), setString(&c.LogLevel)},
+	}
+}
+
+func setString(target *string) func(string) error {
+	return func(value string) error {
+		*target = value
+		return nil
+	}
+}
+
+func setBool(target *bool) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.ParseBool(value)
+		if err == nil {
+			*target = parsed
+		}
+		return err
+	}
+}
+
+func setInt(target *int) func(string) error {
+	return func(value string) error {
+		parsed, err := strconv.Atoi(value)
+		if err == nil {
+			*target = parsed
+		}
+		return err
+	}
+}
+
+func setDuration(target *time.Duration) func(string) error {
+	return func(value string) error {
+		parsed, err := time.ParseDuration(value)
+		if err == nil {
+			*target = parsed
+		}
+		return err
+	}
+}
+
+func oneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Writes content to a file named name in a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "kilabs.yaml", `
+db:
+  driver: sqlite3
+  file: from-file.db
+server:
+  addr: ":4000"
+matching:
+  step: 30m
+log_level: debug
+`)
+	t.Setenv("KILABS_CONFIG", file)
+	t.Setenv("KILABS_ADDR", ":5000")
+	t.Setenv("KILABS_DB_FILE", "from-env.db")
+	t.Setenv("KILABS_MATCHING_HORIZON_DAYS", "30")
+
+	config, args, err := Load([]string{"-addr", ":6000", "-hold-ttl", "5m", "migrate", "up"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range []struct {
+		name      string
+		got, want interface{}
+	}{
+		{"default", config.Matching.Duration, time.Hour},
+		{"file", config.DB.Driver, "sqlite3"},
+		{"file", config.Matching.Step, 30 * time.Minute},
+		{"file", config.LogLevel, "debug"},
+		{"environment over file", config.DB.File, "from-env.db"},
+		{"environment over default", config.Matching.HorizonDays, 30},
+		{"flag over environment", config.Server.Addr, ":6000"},
+		{"flag over default", config.Holds.TTL, 5 * time.Minute},
+		{"arguments", strings.Join(args, " "), "migrate up"},
+	} {
+		if check.got != check.want {
+			t.Errorf("%s: got %v, want %v", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+	file := writeFile(t, "kilabs.toml", `
+[db]
+driver = "memory"
+
+[matching]
+horizon_days = 7
+`)
+	config, _, err := Load([]string{"-config", file})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DB.Driver != "memory" || config.Matching.HorizonDays != 7 {
+		t.Errorf("got driver %q and horizon %d, want memory and 7", config.DB.Driver, config.Matching.HorizonDays)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "unknown YAML setting", file: writeFile(t, "kilabs.yaml", "db:\n  drivr: memory\n"), want: "field drivr not found"},
+		{name: "unknown TOML setting", file: writeFile(t, "kilabs.toml", "[db]\ndrivr = \"memory\"\n"), want: "unknown setting db.drivr"},
+		{name: "other extension", file: writeFile(t, "kilabs.json", "{}"), want: "must have a .yaml, .yml or .toml extension"},
+		{name: "missing file", file: filepath.Join(t.TempDir(), "missing.yaml"), want: "no such file"},
+		{name: "environment", env: map[string]string{"KILABS_DB_MIGRATE": "maybe"}, want: "invalid KILABS_DB_MIGRATE"},
+		{name: "flag", args: []string{"-matching-step", "often"}, want: "invalid -matching-step"},
+		{name: "unknown flag", args: []string{"-colour", "blue"}, want: "flag provided but not defined"},
+		{name: "validation", args: []string{"-db-driver", "oracle"}, want: `db.driver "oracle" must be one of`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("KILABS_CONFIG", test.file)
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			_, _, err := Load(test.args)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("got %v, want an error with %q", err, test.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   string
+	}{
+		{"driver", func(c *Config) { c.DB.Driver = "oracle" }, `db.driver "oracle" must be one of mysql, postgres, sqlite3, memory`},
+		{"host", func(c *Config) { c.DB.Host = "" }, "db.host and db.name are required by mysql"},
+		{"name", func(c *Config) { c.DB.Driver, c.DB.Name = "postgres", "" }, "db.host and db.name are required by postgres"},
+		{"port", func(c *Config) { c.DB.Port = "http" }, `db.port "http" is not a number`},
+		{"file", func(c *Config) { c.DB.Driver, c.DB.File = "sqlite3", "" }, "db.file is required by sqlite3"},
+		{"addr", func(c *Config) { c.Server.Addr = "" }, "server.addr is required"},
+		{"timeouts", func(c *Config) { c.Server.IdleTimeout = -time.Second }, "server timeouts can't be negative"},
+		{"duration", func(c *Config) { c.Matching.Duration = 0 }, "matching.duration must be a whole number of minutes"},
+		{"duration in seconds", func(c *Config) { c.Matching.Duration = 90 * time.Second }, "matching.duration must be a whole number of minutes"},
+		{"step", func(c *Config) { c.Matching.Step = time.Nanosecond }, "matching.step must be a whole number of minutes"},
+		{"horizon", func(c *Config) { c.Matching.HorizonDays = 367 }, "matching.horizon_days must be from 1 to 366"},
+		{"hold ttl", func(c *Config) { c.Holds.TTL = 48 * time.Hour }, "holds.ttl must be positive and at most holds.max_ttl"},
+		{"expiry interval", func(c *Config) { c.Holds.ExpiryInterval = 0 }, "holds.expiry_interval must be positive"},
+		{"log level", func(c *Config) { c.LogLevel = "verbose" }, `log_level "verbose" must be one of debug, info, off`},
+	}
+	if err := Default().Validate(); err != nil {
+		t.Fatalf("the defaults are invalid: %v", err)
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := Default()
+			test.change(config)
+			err := config.Validate()
+			if err == nil || err.Error() != "config: "+test.want {
+				t.Errorf("got %v, want config: %s", err, test.want)
+			}
+		})
+	}
+
+	// Every problem is reported at once
+	config := Default()
+	config.Server.Addr, config.LogLevel = "", "verbose"
+	if err := config.Validate(); err == nil || strings.Count(err.Error(), ";") != 1 {
+		t.Errorf("got %v, want both problems", err)
+	}
+}
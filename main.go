@@ -1,14 +1,38 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/paulofeitor/kilabs-api/app"
 	"github.com/paulofeitor/kilabs-api/config"
 )
 
 func main() {
-	config := config.GetConfig()
+	config, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
 
 	app := &app.App{}
-	app.Initialize(config)
-	app.Run(":3000")
+	if len(args) > 0 && args[0] == "migrate" {
+		if len(args) != 2 {
+			fmt.Fprintln(os.Stderr, "Usage: migrate up|down|status")
+			os.Exit(2)
+		}
+		if err = app.Migrate(config, args[1]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err = app.Initialize(config); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err = app.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 }